@@ -0,0 +1,347 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var (
+	// errNotReady is the error returned if a background process did not
+	// become ready in time.
+	errNotReady = errors.New("background process not ready")
+
+	// errBackgroundExited is the error returned if a background process
+	// exited before it became ready.
+	errBackgroundExited = errors.New("background process exited before it became ready")
+
+	// errUnexpectedStatus is the error returned by WaitForHTTP for responses
+	// other than 200 OK.
+	errUnexpectedStatus = errors.New("unexpected HTTP status")
+)
+
+const (
+	// defaultReadinessTimeout is the time to wait for a background process to
+	// become ready if the step has no timeout.
+	defaultReadinessTimeout = 30 * time.Second
+
+	// probeInterval is the time between two readiness checks.
+	probeInterval = 100 * time.Millisecond
+)
+
+// Probe checks whether a background process is ready.
+type Probe interface {
+	// Ready returns nil if the process is ready. The output contains the
+	// combined stdout and stderr of the process so far.
+	Ready(ctx context.Context, output string) error
+}
+
+// probeFunc is a function implementing the Probe interface.
+type probeFunc func(ctx context.Context, output string) error
+
+// Ready implements the Probe interface.
+func (f probeFunc) Ready(ctx context.Context, output string) error {
+	return f(ctx, output)
+}
+
+// WaitForLog returns a Probe which requires the output of the background
+// process to match the provided regular expression.
+func WaitForLog(pattern string) Probe {
+	re, err := regexp.Compile(pattern)
+
+	return probeFunc(func(_ context.Context, output string) error {
+		if err != nil {
+			return fmt.Errorf("compile log pattern: %w", err)
+		}
+
+		if !re.MatchString(output) {
+			return fmt.Errorf("%w: output does not match %q", errNotReady, pattern)
+		}
+
+		return nil
+	})
+}
+
+// WaitForPort returns a Probe which requires a TCP connection to the provided
+// address, like `localhost:8080`, to succeed.
+func WaitForPort(address string) Probe {
+	return probeFunc(func(ctx context.Context, _ string) error {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return fmt.Errorf("dial %s: %w", address, err)
+		}
+
+		if err := conn.Close(); err != nil {
+			return fmt.Errorf("close connection: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// WaitForFile returns a Probe which requires the file at the provided path to
+// exist.
+func WaitForFile(path string) Probe {
+	return probeFunc(func(context.Context, string) error {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("check file: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// WaitForHTTP returns a Probe which requires a GET request to the provided URL,
+// like `http://localhost:8080/healthz`, to respond with status 200 OK.
+func WaitForHTTP(url string) Probe {
+	return probeFunc(func(ctx context.Context, _ string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request %s: %w", url, err)
+		}
+
+		if err := resp.Body.Close(); err != nil {
+			return fmt.Errorf("close response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%w: %s", errUnexpectedStatus, resp.Status)
+		}
+
+		return nil
+	})
+}
+
+// HideOutput hides the output of the step command. It is still captured for
+// matchers, probes and reports.
+func HideOutput() StepOption {
+	return func(s *step) {
+		s.hideOutput = true
+	}
+}
+
+// StepBackground creates a new step which starts the command as long-running
+// background process, like a web server, and continues with the next step
+// once the provided probe reports it as ready. A nil probe continues
+// immediately. The readiness timeout defaults to 30 seconds and can be changed
+// via WithTimeout.
+//
+// The output of the process is streamed to the run output, unless HideOutput
+// is used. The process and its child processes get terminated when the run
+// completes, fails or is interrupted. Background processes run outside of the
+// session in session mode and are not started during replay.
+func (r *Run) StepBackground(text, command []string, probe Probe, opts ...StepOption) {
+	r.steps = append(r.steps, step{
+		text:       text,
+		command:    command,
+		background: true,
+		probe:      probe,
+	}.applyOptions(opts))
+}
+
+// backgroundProcess is a running background step.
+type backgroundProcess struct {
+	index  int
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	output *syncBuffer
+}
+
+// syncBuffer is a buffer which can be written and read concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	//nolint:wrapcheck // the buffer is transparent
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// hasBackgroundSteps returns true if the run contains any background step.
+func (r *Run) hasBackgroundSteps() bool {
+	for i := range r.steps {
+		if r.steps[i].background {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startBackground starts the command of the step in the background and
+// waits until it is ready.
+func (s *step) startBackground(r *Run, current int) error {
+	// Restarting a step after navigating back replaces the previous process.
+	r.stopBackground(current)
+
+	r.observe(Event{Kind: EventCommandStarted, Step: current, Text: s.text, Command: s.commandLine()})
+
+	if r.player != nil {
+		s.observeCommandFinished(r, current, 0, "", nil)
+		r.record(s, current, 0, "", nil)
+
+		return nil
+	}
+
+	out := r.out
+	if s.hideOutput {
+		out = nil
+	}
+
+	ctx, cancel := context.WithCancel(r.options.Context)
+	p := &backgroundProcess{
+		index:  current,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		output: &syncBuffer{},
+	}
+
+	writer := teeWriter(p.output, out)
+	execution := &Execution{
+		Command:    s.commandLine(),
+		Dir:        r.dir,
+		Env:        r.env,
+		Stdout:     writer,
+		Stderr:     writer,
+		Background: true,
+	}
+
+	executor := r.executorOrDefault()
+
+	go func() {
+		defer close(p.done)
+
+		p.err = executor.Execute(ctx, execution)
+	}()
+
+	r.background = append(r.background, p)
+
+	start := time.Now()
+	err := p.waitReady(r.options.Context, s.probe, s.readinessTimeout())
+
+	duration := time.Since(start)
+	s.observeCommandFinished(r, current, duration, p.output.String(), err)
+	r.record(s, current, duration, p.output.String(), err)
+
+	if s.canFail {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("step background command failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *step) readinessTimeout() time.Duration {
+	if s.timeout > 0 {
+		return s.timeout
+	}
+
+	return defaultReadinessTimeout
+}
+
+// waitReady polls the probe until it succeeds, the process exited or the
+// timeout elapsed.
+func (p *backgroundProcess) waitReady(ctx context.Context, probe Probe, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+
+	for {
+		select {
+		case <-p.done:
+			return p.exitedError()
+		default:
+		}
+
+		if probe == nil {
+			return nil
+		}
+
+		err := probe.Ready(ctx, p.output.String())
+		if err == nil {
+			return nil
+		}
+
+		// Keep the reason of the previous check if this one got aborted.
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			return fmt.Errorf("%w after %s: %w", errNotReady, timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// exitedError returns the error for a process which exited before it
+// became ready, including its output.
+func (p *backgroundProcess) exitedError() error {
+	if p.err == nil {
+		return fmt.Errorf("%w:\n%s", errBackgroundExited, indent(p.output.String()))
+	}
+
+	return fmt.Errorf("%w: %w:\n%s", errBackgroundExited, p.err, indent(p.output.String()))
+}
+
+// stop terminates the process and waits until it exited.
+func (p *backgroundProcess) stop() {
+	p.cancel()
+	<-p.done
+}
+
+// stopBackground terminates the background process of the provided step
+// number, if running.
+func (r *Run) stopBackground(index int) {
+	for i, p := range r.background {
+		if p.index == index {
+			p.stop()
+			r.background = append(r.background[:i], r.background[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// stopAllBackground terminates all background processes in reverse order of
+// their start.
+func (r *Run) stopAllBackground() {
+	for i := len(r.background) - 1; i >= 0; i-- {
+		r.background[i].stop()
+	}
+
+	r.background = nil
+}
@@ -0,0 +1,219 @@
+package demo_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/creack/pty"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("StepBackground", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should succeed to wait for a log line and terminate the process", func() {
+		// Given
+		stopped := filepath.Join(GinkgoT().TempDir(), "stopped")
+		sut.StepBackground(demo.S("Start"),
+			demo.S("trap 'echo > "+stopped+"; exit' TERM; echo server ready; while true; do sleep 0.1; done"),
+			demo.WaitForLog("server ready"),
+		)
+		sut.Step(nil, demo.S("echo next"))
+
+		// When
+		start := time.Now()
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		Expect(out.String()).To(ContainSubstring("server ready\n"))
+		Expect(out.String()).To(ContainSubstring("next\n"))
+		Expect(stopped).To(BeAnExistingFile())
+	})
+
+	It("should succeed to wait for a TCP port", func() {
+		// Given
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+
+		defer listener.Close()
+
+		sut.StepBackground(nil, demo.S("sleep 30"), demo.WaitForPort(listener.Addr().String()))
+
+		// When
+		err = sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should succeed to wait for an HTTP endpoint", func() {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		sut.StepBackground(nil, demo.S("sleep 30"), demo.WaitForHTTP(server.URL))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail if an HTTP endpoint does not respond with 200", func() {
+		// Given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sut.StepBackground(nil, demo.S("sleep 30"), demo.WaitForHTTP(server.URL),
+			demo.WithTimeout(300*time.Millisecond),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("background process not ready after 300ms")))
+		Expect(err).To(MatchError(ContainSubstring("503 Service Unavailable")))
+	})
+
+	It("should succeed to wait for a file", func() {
+		// Given
+		file := filepath.Join(GinkgoT().TempDir(), "ready")
+		sut.StepBackground(nil, demo.S("sleep 0.2 && touch "+file+" && sleep 30"), demo.WaitForFile(file))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(file).To(BeAnExistingFile())
+	})
+
+	It("should fail if the process exits before it is ready", func() {
+		// Given
+		sut.StepBackground(nil, demo.S("echo boom; exit 3"), demo.WaitForLog("never"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("background process exited before it became ready")))
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(err).To(MatchError(ContainSubstring("  boom")))
+	})
+
+	It("should not continue if a background step is not ready", func() {
+		// Given
+		sut.StepBackground(nil, demo.S("exit 1"), demo.WaitForLog("never"), demo.WithTimeout(time.Second))
+		sut.Step(nil, demo.S("echo next"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(out.String()).NotTo(ContainSubstring("next\n"))
+	})
+
+	It("should hide the output of the process", func() {
+		// Given
+		sut.StepBackground(nil, demo.S("echo hidden-output; sleep 30"), demo.WaitForLog("hidden-output"),
+			demo.HideOutput(),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).NotTo(ContainSubstring("hidden-output\n"))
+	})
+
+	It("should not start the process in dry-run", func() {
+		// Given
+		file := filepath.Join(GinkgoT().TempDir(), "started")
+		sut.StepBackground(nil, demo.S("touch "+file+"; sleep 30"), nil)
+		opts.DryRun = true
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = os.Stat(file)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should report the output until the process became ready", func() {
+		// Given
+		sut.StepBackground(nil, demo.S("echo listening; sleep 30"), demo.WaitForLog("listening"))
+
+		// When
+		result := sut.Verify(&opts)
+
+		// Then
+		Expect(result.Passed()).To(BeTrue())
+		Expect(result.Steps).To(HaveLen(1))
+		Expect(result.Steps[0].Output).To(Equal("listening\n"))
+	})
+
+	It("should keep moving the cursor on terminals", func() {
+		// Given
+		ptmx, tty, err := pty.Open()
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(ptmx.Close)
+
+		terminal := &strings.Builder{}
+		copied := make(chan struct{})
+
+		go func() {
+			defer close(copied)
+
+			_, _ = io.Copy(terminal, ptmx)
+		}()
+
+		Expect(sut.SetOutput(tty)).To(Succeed())
+		Expect(sut.SetInput(strings.NewReader(strings.Repeat("\n", 4)))).To(Succeed())
+		sut.StepBackground(nil, demo.S("sleep 10"), nil)
+		sut.Step(nil, demo.S("echo next"))
+
+		opts.Auto = false
+
+		// When
+		err = sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tty.Close()).To(Succeed())
+		Eventually(copied).Should(BeClosed())
+		Expect(terminal.String()).To(ContainSubstring("\x1b[1A"))
+	})
+})
@@ -0,0 +1,127 @@
+package demo
+
+import (
+	"io"
+	"sync"
+)
+
+// broadcaster holds the latest state and sends it to all subscribed clients
+// on every update, like the status events of the remote control and the
+// speaker notes.
+type broadcaster[T any] struct {
+	// buffer is the number of updates buffered for every client before
+	// further ones get dropped.
+	buffer int
+
+	mu          sync.Mutex
+	state       T
+	subscribers map[chan T]struct{}
+	closed      bool
+}
+
+func newBroadcaster[T any](state T, buffer int) *broadcaster[T] {
+	return &broadcaster[T]{
+		buffer:      buffer,
+		state:       state,
+		subscribers: map[chan T]struct{}{},
+	}
+}
+
+// current returns the latest state.
+func (b *broadcaster[T]) current() T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// update modifies the state and sends it to all clients. Slow clients miss
+// updates instead of blocking the demo.
+func (b *broadcaster[T]) update(modify func(*T)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	modify(&b.state)
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- b.state:
+		default:
+		}
+	}
+}
+
+// close ends the streams of all clients.
+func (b *broadcaster[T]) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for subscriber := range b.subscribers {
+		close(subscriber)
+		delete(b.subscribers, subscriber)
+	}
+}
+
+// stream sends the current state and all updates to the client until it
+// disconnects, sending fails or the broadcaster gets closed. Clients never
+// send any data, so reading from the connection detects the disconnect.
+func (b *broadcaster[T]) stream(conn io.Reader, send func(T) error) {
+	updates := b.subscribe()
+	if updates == nil {
+		return
+	}
+
+	defer b.unsubscribe(updates)
+
+	disconnected := make(chan struct{})
+
+	go func() {
+		defer close(disconnected)
+
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	for {
+		select {
+		case state, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			if err := send(state); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		}
+	}
+}
+
+// subscribe registers a new client, which receives the current state first.
+// It returns nil if the broadcaster has been closed.
+func (b *broadcaster[T]) subscribe() chan T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	updates := make(chan T, b.buffer)
+	updates <- b.state
+	b.subscribers[updates] = struct{}{}
+
+	return updates
+}
+
+func (b *broadcaster[T]) unsubscribe(updates chan T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[updates]; ok {
+		close(updates)
+		delete(b.subscribers, updates)
+	}
+}
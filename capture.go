@@ -0,0 +1,74 @@
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errNoCapture is the error returned if the output of a step does not match
+// its capture pattern.
+var errNoCapture = errors.New("output does not match capture pattern")
+
+// capture stores the standard output of a step into a run variable.
+type capture struct {
+	name    string
+	pattern string
+	re      *regexp.Regexp
+	err     error
+}
+
+// CaptureAs stores the standard output of the step command without trailing
+// newlines into the run variable with the provided name. Later steps can
+// reference it in their texts and commands via `${{ .Vars.name }}`.
+func CaptureAs(name string) StepOption {
+	return func(s *step) {
+		s.capture = &capture{name: name}
+	}
+}
+
+// CaptureMatch stores the first group of the provided regular expression, or
+// the whole match if it has no groups, from the standard output of the step
+// command into the run variable with the provided name. The step fails if the
+// output does not match.
+func CaptureMatch(name, pattern string) StepOption {
+	re, err := regexp.Compile(pattern)
+
+	return func(s *step) {
+		s.capture = &capture{name: name, pattern: pattern, re: re, err: err}
+	}
+}
+
+// Var returns the value of the run variable with the provided name and
+// whether it has been captured.
+func (r *Run) Var(name string) (string, bool) {
+	value, ok := r.vars[name]
+
+	return value, ok
+}
+
+// apply extracts the variable from the standard output and stores it.
+func (c *capture) apply(r *Run, stdout string) error {
+	value := strings.TrimRight(stdout, "\n")
+
+	if c.err != nil {
+		return fmt.Errorf("compile capture pattern: %w", c.err)
+	}
+
+	if c.re != nil {
+		match := c.re.FindStringSubmatch(stdout)
+		if match == nil {
+			return fmt.Errorf("%w %q:\n%s", errNoCapture, c.pattern, indent(stdout))
+		}
+
+		value = match[0]
+		if len(match) > 1 {
+			value = match[1]
+		}
+	}
+
+	r.vars[c.name] = value
+
+	return nil
+}
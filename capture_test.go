@@ -0,0 +1,158 @@
+package demo_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Capture", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should succeed to capture the whole output", func() {
+		// Given
+		sut.Step(nil, demo.S("echo abc123"), demo.CaptureAs("id"))
+		sut.Step(demo.S("Show ${{ .Vars.id }}"), demo.S("echo got-${{ .Vars.id }}"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("# Show abc123 [2/2]:"))
+		Expect(out.String()).To(ContainSubstring("> echo got-abc123"))
+		Expect(out.String()).To(ContainSubstring("got-abc123\n"))
+
+		value, ok := sut.Var("id")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("abc123"))
+	})
+
+	It("should capture only the standard output", func() {
+		// Given
+		sut.Step(nil, demo.S("echo progress >&2; echo result"), demo.CaptureAs("value"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("progress"))
+
+		value, _ := sut.Var("value")
+		Expect(value).To(Equal("result"))
+	})
+
+	It("should succeed to capture a regexp group", func() {
+		// Given
+		sut.Step(nil, demo.S("echo 'token: s3cr3t'"), demo.CaptureMatch("token", `token: (\w+)`))
+		sut.Step(nil, demo.S("echo ${{ .Vars.token }}"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> echo s3cr3t"))
+	})
+
+	It("should succeed to capture a whole match without groups", func() {
+		// Given
+		sut.Step(nil, demo.S("echo 'version v1.2.3 installed'"), demo.CaptureMatch("version", `v\d+\.\d+\.\d+`))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		value, _ := sut.Var("version")
+		Expect(value).To(Equal("v1.2.3"))
+	})
+
+	It("should fail if the output does not match the capture pattern", func() {
+		// Given
+		sut.Step(nil, demo.S("echo nothing"), demo.CaptureMatch("id", `id=(\d+)`))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`output does not match capture pattern "id=(\\d+)"`)))
+	})
+
+	It("should fail on an invalid capture pattern", func() {
+		// Given
+		sut.Step(nil, demo.S("echo hi"), demo.CaptureMatch("id", `(`))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("compile capture pattern")))
+	})
+
+	It("should fail on an unknown variable", func() {
+		// Given
+		sut.Step(nil, demo.S("echo ${{ .Vars.missing }}"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`map has no entry for key "missing"`)))
+	})
+
+	It("should not fail on an unknown variable in dry-run", func() {
+		// Given
+		sut.Step(nil, demo.S("echo id"), demo.CaptureAs("id"))
+		sut.Step(nil, demo.S("echo ${{ .Vars.id }}"))
+		opts.DryRun = true
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should keep Go templates with default delimiters", func() {
+		// Given
+		sut.Step(nil, demo.S("echo '{{.State}}'"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("{{.State}}\n"))
+	})
+
+	It("should succeed to capture in session mode", func() {
+		// Given
+		sut.SetSession(true)
+		sut.Step(nil, demo.S("export VALUE=from-session; echo $VALUE"), demo.CaptureAs("value"))
+		sut.Step(nil, demo.S("echo ${{ .Vars.value }}-again"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("from-session-again\n"))
+	})
+})
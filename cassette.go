@@ -0,0 +1,296 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// errCassetteNil is the error returned if no cassette has been provided.
+	errCassetteNil = errors.New("provided cassette is nil")
+
+	// errNotRecorded is the error returned if a command is not part of the
+	// replayed cassette.
+	errNotRecorded = errors.New("command not recorded in cassette")
+)
+
+// cassetteVersion is the version of the cassette file format.
+const cassetteVersion = 1
+
+// Cassette contains the recorded output and exit status of the commands of
+// one or more runs, so that they can be replayed without executing them.
+type Cassette struct {
+	Version int            `json:"version"`
+	Runs    []*CassetteRun `json:"runs"`
+
+	mu sync.Mutex
+}
+
+// CassetteRun contains all recorded commands of a run, identified by its title.
+type CassetteRun struct {
+	Title string          `json:"title"`
+	Steps []*CassetteStep `json:"steps"`
+}
+
+// CassetteStep is a single recorded command execution.
+type CassetteStep struct {
+	Command  string          `json:"command"`
+	ExitCode int             `json:"exitCode"`
+	Events   []CassetteEvent `json:"events"`
+}
+
+// CassetteEvent is a chunk of output written after the delay in seconds
+// since the previous event or the start of the command.
+type CassetteEvent struct {
+	Delay float64 `json:"delay"`
+	Data  string  `json:"data"`
+}
+
+// cassettePlayer replays the recorded commands of a single run.
+type cassettePlayer struct {
+	run    *CassetteRun
+	cursor int
+}
+
+// cassetteWriter records all writes as events while forwarding them.
+type cassetteWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	step    *CassetteStep
+	last    time.Time
+	pending []byte
+}
+
+// NewCassette creates a new empty cassette.
+func NewCassette() *Cassette {
+	return &Cassette{Version: cassetteVersion}
+}
+
+// LoadCassette reads a cassette from the file at the provided path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+
+	c := NewCassette()
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Save writes the cassette to the file at the provided path.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, reportFileMode); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// run returns the recorded run with the provided title, or nil if it does
+// not exist.
+func (c *Cassette) run(title string) *CassetteRun {
+	for _, r := range c.Runs {
+		if r.Title == title {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// reset starts a new recording for the run with the provided title and
+// replaces any previous recording of it.
+func (c *Cassette) reset(title string) *CassetteRun {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r := c.run(title); r != nil {
+		r.Steps = nil
+
+		return r
+	}
+
+	r := &CassetteRun{Title: title}
+	c.Runs = append(c.Runs, r)
+
+	return r
+}
+
+// RecordTo records the output and exit status of every executed command of
+// the run into the provided cassette. Commands are still executed as usual.
+func (r *Run) RecordTo(c *Cassette) error {
+	if c == nil {
+		return errCassetteNil
+	}
+
+	r.cassette = c
+
+	return nil
+}
+
+// ReplayFrom replays the recorded output of every command with the original
+// timing from the provided cassette instead of executing it. The run is
+// matched by its title, the steps by their command.
+func (r *Run) ReplayFrom(c *Cassette) error {
+	if c == nil {
+		return errCassetteNil
+	}
+
+	r.replay = c
+
+	return nil
+}
+
+// recordCommand executes the command and records its output and exit status.
+func (r *Run) recordCommand(ctx context.Context, run *CassetteRun, s *step, out, stdout io.Writer) error {
+	step := &CassetteStep{Command: s.commandLine()}
+	recorder := &cassetteWriter{w: out, step: step, last: time.Now()}
+
+	err := r.execCommand(ctx, s, recorder, stdout)
+	recorder.flush()
+
+	r.cassette.mu.Lock()
+	step.ExitCode = exitCode(err)
+	run.Steps = append(run.Steps, step)
+	r.cassette.mu.Unlock()
+
+	return err
+}
+
+// Write records p as event and forwards it to the underlying writer.
+// Incomplete UTF-8 sequences are recorded together with the next write.
+func (w *cassetteWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := append(w.pending, p...)
+	end := completeLength(data)
+	w.pending = append([]byte(nil), data[end:]...)
+	w.add(data[:end])
+
+	//nolint:wrapcheck // the writer is transparent
+	return w.w.Write(p)
+}
+
+// flush records any remaining incomplete data.
+func (w *cassetteWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.add(w.pending)
+	w.pending = nil
+}
+
+func (w *cassetteWriter) add(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	now := time.Now()
+	w.step.Events = append(w.step.Events, CassetteEvent{
+		Delay: now.Sub(w.last).Seconds(),
+		Data:  string(data),
+	})
+	w.last = now
+}
+
+// play writes the recorded output of the command with its original timing.
+func (p *cassettePlayer) play(ctx context.Context, command string, out io.Writer) error {
+	step := p.find(command)
+	if step == nil {
+		return fmt.Errorf("%w: %s", errNotRecorded, command)
+	}
+
+	for _, event := range step.Events {
+		timer := time.NewTimer(time.Duration(event.Delay * float64(time.Second)))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("replay command: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		if err := write(out, event.Data); err != nil {
+			return err
+		}
+	}
+
+	if step.ExitCode != 0 {
+		return &exitStatusError{code: step.ExitCode}
+	}
+
+	return nil
+}
+
+// find returns the next recorded step for the command, starting after the
+// previously replayed one and wrapping around, so that navigating back
+// replays earlier steps again.
+func (p *cassettePlayer) find(command string) *CassetteStep {
+	if p.run == nil {
+		return nil
+	}
+
+	steps := p.run.Steps
+	for i := range steps {
+		idx := (p.cursor + i) % len(steps)
+		if steps[idx].Command == command {
+			p.cursor = idx + 1
+
+			return steps[idx]
+		}
+	}
+
+	return nil
+}
+
+// withCassette records all runs into the cassette file at the provided path
+// while executing fn.
+func withCassette(path string, runs []*Run, fn func() error) error {
+	c := NewCassette()
+
+	for _, r := range runs {
+		if err := r.RecordTo(c); err != nil {
+			return err
+		}
+	}
+
+	runErr := fn()
+
+	return errors.Join(runErr, c.Save(path))
+}
+
+// replayRuns replays all runs from the cassette file at the provided path.
+func replayRuns(path string, runs []*Run) error {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		if err := r.ReplayFrom(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
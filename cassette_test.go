@@ -0,0 +1,148 @@
+package demo_test
+
+import (
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Cassette", func() {
+	var opts demo.Options
+
+	BeforeEach(func() {
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	newRun := func(out *strings.Builder) *demo.Run {
+		r := demo.NewRun("Cassette")
+		r.Step(nil, demo.S("echo value=$CASSETTE_VALUE"))
+		r.StepCanFail(nil, demo.S("exit 3"))
+		Expect(r.SetOutput(out)).To(Succeed())
+
+		return r
+	}
+
+	It("should fail to record or replay without cassette", func() {
+		// Given
+		sut := demo.NewRun("Cassette")
+
+		// When
+		recordErr := sut.RecordTo(nil)
+		replayErr := sut.ReplayFrom(nil)
+
+		// Then
+		Expect(recordErr).To(HaveOccurred())
+		Expect(replayErr).To(HaveOccurred())
+	})
+
+	It("should succeed to record and replay a run", func() {
+		// Given
+		cassette := demo.NewCassette()
+		recorded := &strings.Builder{}
+		sut := newRun(recorded)
+		sut.SetEnv("CASSETTE_VALUE=recorded")
+		Expect(sut.RecordTo(cassette)).To(Succeed())
+		Expect(sut.RunWithOptions(&opts)).To(Succeed())
+
+		path := filepath.Join(GinkgoT().TempDir(), "demo.cassette")
+		Expect(cassette.Save(path)).To(Succeed())
+
+		loaded, err := demo.LoadCassette(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		replayed := &strings.Builder{}
+		replay := newRun(replayed)
+		Expect(replay.ReplayFrom(loaded)).To(Succeed())
+
+		// When
+		err = replay.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded.Runs).To(HaveLen(1))
+		Expect(loaded.Runs[0].Steps).To(HaveLen(2))
+		Expect(loaded.Runs[0].Steps[0].ExitCode).To(BeZero())
+		Expect(loaded.Runs[0].Steps[1].ExitCode).To(Equal(3))
+		Expect(recorded.String()).To(ContainSubstring("value=recorded"))
+		Expect(replayed.String()).To(ContainSubstring("value=recorded"))
+	})
+
+	It("should replay the recorded exit status", func() {
+		// Given
+		cassette := demo.NewCassette()
+		sut := demo.NewRun("Exit")
+		sut.StepCanFail(nil, demo.S("exit 3"))
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(sut.RecordTo(cassette)).To(Succeed())
+		Expect(sut.RunWithOptions(&opts)).To(Succeed())
+
+		replay := demo.NewRun("Exit")
+		replay.Step(nil, demo.S("exit 3"))
+		Expect(replay.SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(replay.ReplayFrom(cassette)).To(Succeed())
+
+		// When
+		err := replay.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+	})
+
+	It("should fail to replay a command which has not been recorded", func() {
+		// Given
+		replay := demo.NewRun("Missing")
+		replay.Step(nil, demo.S("echo missing"))
+		Expect(replay.SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(replay.ReplayFrom(demo.NewCassette())).To(Succeed())
+
+		// When
+		err := replay.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("command not recorded in cassette: echo missing")))
+	})
+
+	It("should fail to load a non existing cassette", func() {
+		// Given
+		path := filepath.Join(GinkgoT().TempDir(), "missing.cassette")
+
+		// When
+		_, err := demo.LoadCassette(path)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should record and replay via the demo flags", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.cassette")
+
+		newDemo := func() *demo.Demo {
+			r := demo.NewRun("Flags")
+			r.Step(nil, demo.S("echo from cassette"))
+
+			sut := demo.New()
+			sut.Add(r, "run1", "first run")
+
+			return sut
+		}
+
+		withArgs([]string{
+			appName, "--run1", "--cassette", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			Expect(newDemo().RunE()).To(Succeed())
+		})
+
+		withArgs([]string{
+			appName, "--run1", "--replay", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			Expect(newDemo().RunE()).To(Succeed())
+		})
+
+		cassette, err := demo.LoadCassette(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cassette.Runs[0].Steps[0].Events[0].Data).To(Equal("from cassette\n"))
+	})
+})
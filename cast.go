@@ -0,0 +1,206 @@
+package demo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+)
+
+const (
+	// castVersion is the asciicast format version written by the CastWriter.
+	castVersion = 2
+
+	// castEventOutput is the asciicast event type for terminal output.
+	castEventOutput = "o"
+
+	// defaultCastWidth is the terminal width used if it cannot be detected.
+	defaultCastWidth = 80
+
+	// defaultCastHeight is the terminal height used if it cannot be detected.
+	defaultCastHeight = 24
+)
+
+// CastWriter is an io.Writer which records everything written to it as
+// asciinema asciicast v2 stream. Every write results in a single output
+// event, timestamped relative to the creation of the writer. It can be
+// attached to a run via Run.SetOutput.
+type CastWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	start   time.Time
+	pending []byte
+
+	// cr is true if the last recorded byte was a carriage return.
+	cr bool
+}
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// NewCastWriter creates a new CastWriter for a terminal of the provided
+// size and writes the asciicast header to w.
+func NewCastWriter(w io.Writer, width, height int) (*CastWriter, error) {
+	if w == nil {
+		return nil, errOutputNil
+	}
+
+	start := time.Now()
+	header := castHeader{
+		Version:   castVersion,
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM")},
+	}
+
+	if err := writeJSONLine(w, header); err != nil {
+		return nil, err
+	}
+
+	return &CastWriter{w: w, start: start}, nil
+}
+
+// Write records p as output event. Incomplete UTF-8 sequences at the end
+// of p are buffered until the next write. Line feeds get recorded as carriage
+// return and line feed, like a terminal outputs them.
+func (c *CastWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := append(c.pending, p...)
+	end := completeLength(data)
+	c.pending = append([]byte(nil), data[end:]...)
+
+	if end == 0 {
+		return len(p), nil
+	}
+
+	output := c.translateNewlines(data[:end])
+
+	elapsed := time.Since(c.start).Seconds()
+	if err := writeJSONLine(c.w, []any{elapsed, castEventOutput, output}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// translateNewlines converts lone line feeds into carriage return and line
+// feed, while keeping existing ones, like the onlcr setting of a terminal.
+func (c *CastWriter) translateNewlines(data []byte) string {
+	var b strings.Builder
+
+	for _, ch := range data {
+		if ch == '\n' && !c.cr {
+			b.WriteByte('\r')
+		}
+
+		b.WriteByte(ch)
+		c.cr = ch == '\r'
+	}
+
+	return b.String()
+}
+
+// completeLength returns the length of data without a trailing incomplete
+// UTF-8 sequence.
+func completeLength(data []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		start := len(data) - i
+		if utf8.RuneStart(data[start]) {
+			if utf8.FullRune(data[start:]) {
+				return len(data)
+			}
+
+			return start
+		}
+	}
+
+	return len(data)
+}
+
+func writeJSONLine(w io.Writer, v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal cast event: %w", err)
+	}
+
+	return write(w, string(line)+"\n")
+}
+
+// terminalSize returns the size of the provided terminal or the default
+// cast size if it cannot be detected.
+func terminalSize(f *os.File) (width, height int) {
+	w, h, err := term.GetSize(int(f.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		return defaultCastWidth, defaultCastHeight
+	}
+
+	return w, h
+}
+
+// withRecording tees the output of all runs and the root command into the
+// cast file at the provided path while executing fn. The previous outputs
+// get restored afterwards.
+func withRecording(path string, root *cli.Command, runs []*Run, fn func() error) (err error) {
+	//nolint:gosec // the recording path is provided by the user
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create recording: %w", err)
+	}
+
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close recording: %w", closeErr))
+		}
+	}()
+
+	width, height := terminalSize(os.Stdout)
+
+	cast, err := NewCastWriter(f, width, height)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		out := r.out
+		r.out = &recordingWriter{Writer: io.MultiWriter(out, cast), out: out}
+
+		defer func() { r.out = out }()
+	}
+
+	// The menu writes to the root command.
+	writer := root.Writer
+	root.Writer = &recordingWriter{Writer: io.MultiWriter(writer, cast), out: writer}
+
+	defer func() { root.Writer = writer }()
+
+	return fn()
+}
+
+// recordingWriter writes to the output and the recording, while the output
+// stays detectable as terminal.
+type recordingWriter struct {
+	io.Writer
+
+	out io.Writer
+}
+
+// Unwrap returns the output without the recording.
+func (w *recordingWriter) Unwrap() io.Writer {
+	return w.out
+}
@@ -0,0 +1,206 @@
+package demo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+func parseCast(data []byte) (map[string]any, [][]any) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	Expect(lines).NotTo(BeEmpty())
+
+	header := map[string]any{}
+	Expect(json.Unmarshal([]byte(lines[0]), &header)).To(Succeed())
+
+	events := make([][]any, 0, len(lines)-1)
+
+	for _, line := range lines[1:] {
+		var event []any
+		Expect(json.Unmarshal([]byte(line), &event)).To(Succeed())
+		Expect(event).To(HaveLen(3))
+		events = append(events, event)
+	}
+
+	return header, events
+}
+
+var _ = Describe("CastWriter", func() {
+	It("should fail to create with nil output", func() {
+		// Given
+		// When
+		_, err := demo.NewCastWriter(nil, 80, 24)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should succeed to record a run", func() {
+		// Given
+		buf := &bytes.Buffer{}
+		sut, err := demo.NewCastWriter(buf, 100, 30)
+		Expect(err).ToNot(HaveOccurred())
+
+		r := demo.NewRun("Cast Title")
+		r.Step(demo.S("Cast step"), demo.S("echo cast output"))
+		Expect(r.SetOutput(sut)).To(Succeed())
+
+		// When
+		err = r.RunWithOptions(&demo.Options{Auto: true, TypewriterSpeed: 1})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		header, events := parseCast(buf.Bytes())
+		Expect(header).To(HaveKeyWithValue("version", BeNumerically("==", 2)))
+		Expect(header).To(HaveKeyWithValue("width", BeNumerically("==", 100)))
+		Expect(header).To(HaveKeyWithValue("height", BeNumerically("==", 30)))
+
+		output := &strings.Builder{}
+		last := 0.0
+
+		for _, event := range events {
+			timestamp, ok := event[0].(float64)
+			Expect(ok).To(BeTrue())
+			Expect(timestamp).To(BeNumerically(">=", last))
+			Expect(event[1]).To(Equal("o"))
+
+			last = timestamp
+			output.WriteString(event[2].(string))
+		}
+
+		Expect(output.String()).To(ContainSubstring("Cast Title"))
+		Expect(output.String()).To(ContainSubstring("> echo cast output"))
+		Expect(output.String()).To(ContainSubstring("cast output\r\n"))
+		Expect(output.String()).NotTo(MatchRegexp("[^\r]\n"))
+	})
+
+	It("should record line feeds with carriage returns", func() {
+		// Given
+		buf := &bytes.Buffer{}
+		sut, err := demo.NewCastWriter(buf, 80, 24)
+		Expect(err).ToNot(HaveOccurred())
+
+		// When
+		_, err = sut.Write([]byte("one\ntwo\r\nthree\r"))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = sut.Write([]byte("\nfour\n"))
+		Expect(err).ToNot(HaveOccurred())
+
+		// Then
+		_, events := parseCast(buf.Bytes())
+		Expect(events).To(HaveLen(2))
+		Expect(events[0][2]).To(Equal("one\r\ntwo\r\nthree\r"))
+		Expect(events[1][2]).To(Equal("\nfour\r\n"))
+	})
+
+	It("should buffer incomplete UTF-8 sequences", func() {
+		// Given
+		buf := &bytes.Buffer{}
+		sut, err := demo.NewCastWriter(buf, 80, 24)
+		Expect(err).ToNot(HaveOccurred())
+
+		ellipsis := []byte("…")
+
+		// When
+		_, err = sut.Write(ellipsis[:1])
+		Expect(err).ToNot(HaveOccurred())
+		_, err = sut.Write(ellipsis[1:])
+		Expect(err).ToNot(HaveOccurred())
+
+		// Then
+		_, events := parseCast(buf.Bytes())
+		Expect(events).To(HaveLen(1))
+		Expect(events[0][2]).To(Equal("…"))
+	})
+})
+
+var _ = Describe("Demo recording", func() {
+	It("should record a demo into a cast file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.cast")
+
+		withArgs([]string{
+			appName, "--run1", "--record", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			r := demo.NewRun("Recorded Run")
+			r.Step(nil, demo.S("echo recorded"))
+
+			sut := demo.New()
+			sut.Add(r, "run1", "first run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, events := parseCast(data)
+		Expect(events).NotTo(BeEmpty())
+	})
+
+	It("should record and restore the custom output of a run", func() {
+		// Given
+		path := filepath.Join(GinkgoT().TempDir(), "demo.cast")
+		out := &bytes.Buffer{}
+
+		r := demo.NewRun("Recorded Run")
+		Expect(r.SetOutput(out)).To(Succeed())
+		r.Step(nil, demo.S("echo recorded"))
+
+		sut := demo.New()
+		sut.Add(r, "run1", "first run")
+
+		// When
+		withArgs([]string{
+			appName, "--run1", "--record", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		// Then
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("recorded"))
+		Expect(out.String()).To(ContainSubstring("recorded"))
+
+		out.Reset()
+		Expect(r.RunWithOptions(&demo.Options{Auto: true, Immediate: true})).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("recorded"))
+		Expect(os.ReadFile(path)).To(Equal(data))
+	})
+
+	It("should record the menu", func() {
+		// Given
+		path := filepath.Join(GinkgoT().TempDir(), "demo.cast")
+		menu := &strings.Builder{}
+
+		r := demo.NewRun("Recorded Run")
+		Expect(r.SetOutput(&bytes.Buffer{})).To(Succeed())
+		r.Step(nil, demo.S("echo recorded"))
+
+		sut := demo.New()
+		sut.Writer = menu
+		sut.Reader = strings.NewReader("\r\r")
+		sut.Add(r, "run1", "first run")
+
+		// When
+		withArgs([]string{
+			appName, "--menu", "--no-color", "--record", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		// Then
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("Select a demo"))
+		Expect(menu.String()).To(ContainSubstring("Select a demo"))
+		Expect(sut.Writer).To(BeIdenticalTo(menu))
+	})
+})
@@ -0,0 +1,120 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// stepHook is a setup or cleanup function of a step, which gets bound to the
+// working directory, environment and executor of the run when the step gets
+// executed.
+type stepHook func(r *Run) func(context.Context) error
+
+// WithSetup calls the provided function every time before the command of the
+// step gets executed. An error fails the step without executing its command.
+func WithSetup(fn func(context.Context) error) StepOption {
+	return func(s *step) {
+		s.setup = append(s.setup, func(*Run) func(context.Context) error { return fn })
+	}
+}
+
+// WithCleanup registers the provided function for cleaning up after the step
+// once its command gets executed for the first time, like testing.T.Cleanup
+// does. All cleanup functions of a run are called in last-in, first-out order
+// when the run ends, fails or gets interrupted. They receive a context which
+// is not cancelled together with the run.
+func WithCleanup(fn func(context.Context) error) StepOption {
+	return func(s *step) {
+		s.cleanup = append(s.cleanup, func(*Run) func(context.Context) error { return fn })
+	}
+}
+
+// WithSetupCommands executes the provided commands like WithSetup, within the
+// working directory and environment of the step and without showing their
+// output.
+func WithSetupCommands(commands ...string) StepOption {
+	return func(s *step) {
+		s.setup = append(s.setup, ensureHook(commands))
+	}
+}
+
+// WithCleanupCommands registers the provided commands for cleaning up after
+// the step like WithCleanup, which get executed within the working directory
+// and environment of the step and without showing their output.
+func WithCleanupCommands(commands ...string) StepOption {
+	return func(s *step) {
+		s.cleanup = append(s.cleanup, ensureHook(commands))
+	}
+}
+
+// ensureHook returns a step hook which executes the commands in order.
+func ensureHook(commands []string) stepHook {
+	return func(r *Run) func(context.Context) error {
+		executor := r.executorOrDefault()
+		dir := r.dir
+		env := slices.Clone(r.env)
+
+		return func(ctx context.Context) error {
+			for _, command := range commands {
+				if err := executor.Execute(ctx, &Execution{Command: command, Dir: dir, Env: env}); err != nil {
+					return fmt.Errorf("run command %q: %w", command, err)
+				}
+			}
+
+			return nil
+		}
+	}
+}
+
+// prepare calls the setup functions of the step and registers its cleanup
+// functions, unless they have been registered before.
+func (s *step) prepare(r *Run, current int) error {
+	for _, setup := range s.setup {
+		if err := setup(r)(r.options.Context); err != nil {
+			return fmt.Errorf("step setup failed: %w", err)
+		}
+	}
+
+	if len(s.cleanup) == 0 {
+		return nil
+	}
+
+	if _, ok := r.registered[current]; ok {
+		return nil
+	}
+
+	r.registered[current] = struct{}{}
+
+	for _, cleanup := range s.cleanup {
+		r.cleanups = append(r.cleanups, cleanup(r))
+	}
+
+	return nil
+}
+
+// finish calls the registered step cleanup functions in reverse order and the
+// cleanup function of the run afterwards. All of them are called, even if
+// some fail.
+func (r *Run) finish() error {
+	//nolint:contextcheck // cleanups have to run after the run got cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.options.Context), cleanupTimeout)
+	defer cancel()
+
+	var errs []error
+
+	for _, cleanup := range slices.Backward(r.cleanups) {
+		if err := cleanup(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("step cleanup failed: %w", err))
+		}
+	}
+
+	r.cleanups = nil
+
+	if err := r.cleanup(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,184 @@
+package demo_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Cleanup", func() {
+	var (
+		sut   *demo.Run
+		opts  demo.Options
+		calls []string
+	)
+
+	call := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			calls = append(calls, name)
+
+			return nil
+		}
+	}
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+
+		calls = nil
+		sut.Cleanup(func() error {
+			calls = append(calls, "run")
+
+			return nil
+		})
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should call the step cleanups in reverse order before the run cleanup", func() {
+		// Given
+		sut.Step(nil, demo.S("echo first"), demo.WithCleanup(call("first-a")), demo.WithCleanup(call("first-b")))
+		sut.Step(nil, demo.S("echo second"), demo.WithCleanup(call("second")))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal([]string{"second", "first-b", "first-a", "run"}))
+	})
+
+	It("should call the cleanups of executed steps if a step fails", func() {
+		// Given
+		sut.Step(nil, demo.S("echo first"), demo.WithCleanup(call("first")))
+		sut.Step(nil, demo.S("exit 1"), demo.WithCleanup(call("failing")))
+		sut.Step(nil, demo.S("echo never"), demo.WithCleanup(call("never")))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("step command failed")))
+		Expect(calls).To(Equal([]string{"failing", "first", "run"}))
+	})
+
+	It("should call the cleanups if the run gets interrupted", func() {
+		// Given
+		ctx, cancel := context.WithCancel(context.Background())
+		opts.Context = ctx
+
+		sut.Step(nil, demo.S("sleep 10"),
+			demo.WithSetup(func(context.Context) error {
+				cancel()
+
+				return nil
+			}),
+			demo.WithCleanup(func(ctx context.Context) error {
+				calls = append(calls, "sleep")
+
+				return ctx.Err()
+			}),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(calls).To(Equal([]string{"sleep", "run"}))
+	})
+
+	It("should call all cleanups even if some fail", func() {
+		// Given
+		sut.Step(nil, demo.S("echo first"), demo.WithCleanup(call("first")))
+		sut.Step(nil, demo.S("echo second"), demo.WithCleanup(func(context.Context) error {
+			return errCleanupFailed
+		}))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(errCleanupFailed))
+		Expect(calls).To(Equal([]string{"first", "run"}))
+	})
+
+	It("should register the cleanups of rerun steps only once", func() {
+		// Given
+		opts.Auto = false
+		Expect(sut.SetInput(strings.NewReader("\nr\n\n\n"))).To(Succeed())
+		sut.Step(nil, demo.S("echo rerun"), demo.WithCleanup(call("rerun")))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal([]string{"rerun", "run"}))
+	})
+
+	It("should not execute the command if the setup fails", func() {
+		// Given
+		out := &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+		sut.Step(nil, demo.S("echo executed"),
+			demo.WithSetup(func(context.Context) error { return errSetupFailed }),
+			demo.WithCleanup(call("step")),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(errSetupFailed))
+		Expect(strings.Count(out.String(), "executed\n")).To(Equal(1))
+		Expect(calls).To(Equal([]string{"run"}))
+	})
+
+	It("should not call the setup and cleanup in dry-run mode", func() {
+		// Given
+		opts.DryRun = true
+		sut.Step(nil, demo.S("echo dry"), demo.WithSetup(call("setup")), demo.WithCleanup(call("step")))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal([]string{"run"}))
+	})
+
+	It("should execute setup and cleanup commands in the working directory", func() {
+		// Given
+		dir := GinkgoT().TempDir()
+		sut.SetWorkDir(dir)
+		sut.Step(nil, demo.S("test -f prepared"),
+			demo.WithSetupCommands("touch prepared"),
+			demo.WithCleanupCommands("rm prepared", "touch cleaned"),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(dir, "prepared")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dir, "cleaned")).To(BeAnExistingFile())
+	})
+
+	It("should fail if a cleanup command fails", func() {
+		// Given
+		sut.Step(nil, demo.S("echo test"), demo.WithCleanupCommands("exit 2"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`step cleanup failed: run command "exit 2"`)))
+		Expect(calls).To(Equal([]string{"run"}))
+	})
+})
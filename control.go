@@ -0,0 +1,398 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+var (
+	// errCommandPending is the error returned if a remote command arrives
+	// while the previous one did not get processed yet.
+	errCommandPending = errors.New("another command is pending")
+
+	// errControlHeaderMissing is the error returned for remote commands
+	// without the ControlHeader.
+	errControlHeaderMissing = errors.New("missing " + ControlHeader + " header")
+)
+
+// ControlHeader is the header required for all commands of the remote
+// control, like `X-Demo-Control: 1`. Browsers do not send custom headers for
+// cross-site requests without the consent of the server, so other web pages
+// cannot navigate the demo.
+const ControlHeader = "X-Demo-Control"
+
+const (
+	// controlReadHeaderTimeout is the maximum time to read the request
+	// headers of the remote control.
+	controlReadHeaderTimeout = 5 * time.Second
+
+	// controlEventBuffer is the number of status events buffered for every
+	// event stream before further ones get dropped.
+	controlEventBuffer = 16
+)
+
+// RemoteControl is an HTTP server for navigating through the steps of runs
+// remotely, for example from a phone or a presentation clicker app. It
+// provides the following endpoints:
+//
+//	POST /next         continue with the next step
+//	POST /back         restart the previous step
+//	POST /jump/{step}  restart at the provided step number
+//	POST /pause        hold the automatic mode at the current step
+//	POST /resume       continue the automatic mode
+//	GET  /status       the current status as JSON
+//	GET  /events       a WebSocket stream of status changes as JSON
+//
+// All POST requests require the ControlHeader with a non-empty value. The
+// server has no further authentication, so it should only listen on trusted
+// interfaces.
+type RemoteControl struct {
+	server   *http.Server
+	listener net.Listener
+	commands chan navAction
+	toggled  chan struct{}
+	status   *broadcaster[controlStatus]
+}
+
+// controlStatus is the status reported by the remote control.
+type controlStatus struct {
+	Title   string `json:"title"`
+	Step    int    `json:"step"`
+	Steps   int    `json:"steps"`
+	Waiting bool   `json:"waiting"`
+	Paused  bool   `json:"paused"`
+}
+
+// NewRemoteControl starts serving the remote control on the provided address,
+// like `127.0.0.1:8080`. A port of 0 chooses a free one, which is available
+// via Addr.
+func NewRemoteControl(address string) (*RemoteControl, error) {
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", address, err)
+	}
+
+	c := &RemoteControl{
+		listener: listener,
+		commands: make(chan navAction, 1),
+		toggled:  make(chan struct{}, 1),
+		status:   newBroadcaster(controlStatus{}, controlEventBuffer),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /next", requireControlHeader(c.handleCommand(func(*http.Request) (navAction, error) {
+		return navAction{kind: navNext}, nil
+	})))
+	mux.HandleFunc("POST /back", requireControlHeader(c.handleCommand(func(*http.Request) (navAction, error) {
+		return navAction{kind: navBack}, nil
+	})))
+	mux.HandleFunc("POST /jump/{step}", requireControlHeader(c.handleCommand(func(req *http.Request) (navAction, error) {
+		n, err := strconv.Atoi(req.PathValue("step"))
+		if err != nil {
+			return navAction{}, fmt.Errorf("parse step number: %w", err)
+		}
+
+		return navAction{kind: navJump, step: n}, nil
+	})))
+	mux.HandleFunc("POST /pause", requireControlHeader(c.handlePause(true)))
+	mux.HandleFunc("POST /resume", requireControlHeader(c.handlePause(false)))
+	mux.HandleFunc("GET /status", c.handleStatus)
+	mux.Handle("GET /events", websocket.Handler(c.handleEvents))
+
+	c.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: controlReadHeaderTimeout,
+	}
+
+	go func() {
+		_ = c.server.Serve(listener)
+	}()
+
+	return c, nil
+}
+
+// Addr returns the address the remote control listens on.
+func (c *RemoteControl) Addr() string {
+	return c.listener.Addr().String()
+}
+
+// Close stops the remote control and ends all event streams.
+func (c *RemoteControl) Close() error {
+	c.status.close()
+
+	if err := c.server.Close(); err != nil {
+		return fmt.Errorf("close remote control: %w", err)
+	}
+
+	return nil
+}
+
+// SetRemoteControl enables navigating through the steps of the run via the
+// provided remote control, in addition to the keyboard.
+func (r *Run) SetRemoteControl(control *RemoteControl) {
+	r.control = control
+}
+
+// withRemoteControl serves the remote control for the runs while executing
+// the provided function.
+func withRemoteControl(address string, runs []*Run, fn func() error) error {
+	control, err := NewRemoteControl(address)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		r.SetRemoteControl(control)
+	}
+
+	return errors.Join(fn(), control.Close())
+}
+
+// requireControlHeader rejects requests without the ControlHeader, which
+// protects against cross-site requests of other web pages.
+func requireControlHeader(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(ControlHeader) == "" {
+			http.Error(w, errControlHeaderMissing.Error(), http.StatusForbidden)
+
+			return
+		}
+
+		next(w, req)
+	}
+}
+
+// handleCommand returns a handler which queues the parsed navigation.
+func (c *RemoteControl) handleCommand(parse func(*http.Request) (navAction, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		nav, err := parse(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		select {
+		case c.commands <- nav:
+		default:
+			http.Error(w, errCommandPending.Error(), http.StatusConflict)
+
+			return
+		}
+
+		c.writeStatus(w)
+	}
+}
+
+// handlePause returns a handler which pauses or resumes the automatic mode.
+func (c *RemoteControl) handlePause(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		c.update(func(s *controlStatus) { s.Paused = paused })
+
+		select {
+		case c.toggled <- struct{}{}:
+		default:
+		}
+
+		c.writeStatus(w)
+	}
+}
+
+func (c *RemoteControl) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	c.writeStatus(w)
+}
+
+// handleEvents streams the current status and all further changes until the
+// client disconnects or the remote control gets closed.
+func (c *RemoteControl) handleEvents(conn *websocket.Conn) {
+	c.status.stream(conn, func(status controlStatus) error {
+		return websocket.JSON.Send(conn, status)
+	})
+}
+
+func (c *RemoteControl) writeStatus(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(c.currentStatus()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (c *RemoteControl) currentStatus() controlStatus {
+	return c.status.current()
+}
+
+// update modifies the status and notifies all event streams.
+func (c *RemoteControl) update(modify func(*controlStatus)) {
+	c.status.update(modify)
+}
+
+func (c *RemoteControl) paused() bool {
+	return c.currentStatus().Paused
+}
+
+// setControlStep reports the step of the run the demo is currently at.
+func (r *Run) setControlStep(current, maximum int) {
+	if r.control == nil {
+		return
+	}
+
+	r.control.update(func(s *controlStatus) {
+		s.Title = r.heading
+		s.Step = current
+		s.Steps = maximum
+	})
+}
+
+// setControlWaiting reports whether the demo waits for the presenter.
+func (r *Run) setControlWaiting(waiting bool) {
+	if r.control == nil {
+		return
+	}
+
+	r.control.update(func(s *controlStatus) { s.Waiting = waiting })
+}
+
+// inputResult is the result of reading the navigation from the keyboard.
+type inputResult struct {
+	nav navAction
+	err error
+}
+
+// awaitNavigation reads the navigation of the presenter from the keyboard or
+// the remote control, whatever comes first. The keyboard gets ignored once the
+// input is exhausted.
+func (r *Run) awaitNavigation(raw bool) (navAction, error) {
+	if r.control == nil {
+		return r.readInput(raw)
+	}
+
+	r.setControlWaiting(true)
+	defer r.setControlWaiting(false)
+
+	if r.inFile != nil && inputPollable {
+		return r.pollNavigation(raw)
+	}
+
+	// Readers other than files cannot be polled, so the read stays pending
+	// after a remote command and the next input applies to the next wait.
+	if r.pending == nil && !r.inputClosed {
+		pending := make(chan inputResult, 1)
+
+		go func() {
+			nav, err := r.readInput(raw)
+			pending <- inputResult{nav: nav, err: err}
+		}()
+
+		r.pending = pending
+	}
+
+	for {
+		select {
+		case result := <-r.pending:
+			r.pending = nil
+
+			if errors.Is(result.err, io.EOF) {
+				r.inputClosed = true
+
+				continue
+			}
+
+			return result.nav, result.err
+		case nav := <-r.control.commands:
+			return nav, nil
+		case <-r.options.Context.Done():
+			return navAction{}, fmt.Errorf("wait for navigation: %w", r.options.Context.Err())
+		}
+	}
+}
+
+// pollNavigation waits for the input file to become readable in between
+// checking the remote control. The keyboard gets only read if input is
+// available, so that no read outlives the wait and consumes the input of
+// commands or later runs.
+func (r *Run) pollNavigation(raw bool) (navAction, error) {
+	for {
+		if r.inputClosed {
+			select {
+			case nav := <-r.control.commands:
+				return nav, nil
+			case <-r.options.Context.Done():
+				return navAction{}, fmt.Errorf("wait for navigation: %w", r.options.Context.Err())
+			}
+		}
+
+		select {
+		case nav := <-r.control.commands:
+			return nav, nil
+		case <-r.options.Context.Done():
+			return navAction{}, fmt.Errorf("wait for navigation: %w", r.options.Context.Err())
+		default:
+		}
+
+		ready := r.in.Buffered() > 0
+		if !ready {
+			var err error
+			if ready, err = inputReady(r.inFile); err != nil {
+				return navAction{}, err
+			}
+		}
+
+		if !ready {
+			continue
+		}
+
+		nav, err := r.readInput(raw)
+		if errors.Is(err, io.EOF) {
+			r.inputClosed = true
+
+			continue
+		}
+
+		return nav, err
+	}
+}
+
+// awaitTimeout waits for the automatic mode timeout, which can be paused and
+// skipped via the remote control.
+func (r *Run) awaitTimeout() (navAction, error) {
+	if r.control == nil {
+		time.Sleep(r.options.AutoTimeout)
+
+		return navAction{kind: navNext}, nil
+	}
+
+	r.setControlWaiting(true)
+	defer r.setControlWaiting(false)
+
+	timer := time.NewTimer(r.options.AutoTimeout)
+	defer timer.Stop()
+
+	for {
+		var expired <-chan time.Time
+		if !r.control.paused() {
+			expired = timer.C
+		}
+
+		select {
+		case <-expired:
+			return navAction{kind: navNext}, nil
+		case nav := <-r.control.commands:
+			return nav, nil
+		case <-r.control.toggled:
+			// The full timeout applies again after resuming.
+			timer.Reset(r.options.AutoTimeout)
+		case <-r.options.Context.Done():
+			return navAction{}, fmt.Errorf("wait for navigation: %w", r.options.Context.Err())
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package demo_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+	"golang.org/x/net/websocket"
+)
+
+var _ = Describe("RemoteControl", func() {
+	var (
+		sut     *demo.Run
+		out     *strings.Builder
+		opts    demo.Options
+		control *demo.RemoteControl
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Remote")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+		Expect(sut.SetInput(strings.NewReader(""))).To(Succeed())
+
+		var err error
+		control, err = demo.NewRemoteControl("127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(control.Close)
+
+		sut.SetRemoteControl(control)
+		opts = demo.Options{Immediate: true}
+	})
+
+	post := func(path string) int {
+		url := "http://" + control.Addr() + path
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, http.NoBody)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set(demo.ControlHeader, "1")
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		return resp.StatusCode
+	}
+
+	// send retries the command until it is accepted, because only a single
+	// one can be pending.
+	send := func(path string) {
+		Eventually(func() int { return post(path) }).Should(Equal(http.StatusOK))
+	}
+
+	status := func() map[string]any {
+		resp, err := http.Get("http://" + control.Addr() + "/status")
+		Expect(err).ToNot(HaveOccurred())
+
+		defer resp.Body.Close()
+
+		result := map[string]any{}
+		Expect(json.NewDecoder(resp.Body).Decode(&result)).To(Succeed())
+
+		return result
+	}
+
+	start := func() chan error {
+		done := make(chan error, 1)
+
+		go func() {
+			done <- sut.RunWithOptions(&opts)
+		}()
+
+		return done
+	}
+
+	It("should advance the steps", func() {
+		// Given
+		sut.Step(demo.S("First"), demo.S("echo out-$((1))"))
+		sut.Step(demo.S("Second"), demo.S("echo out-$((2))"))
+
+		// When
+		done := start()
+
+		for range 4 {
+			send("/next")
+		}
+
+		// Then
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(out.String()).To(ContainSubstring("out-1\n"))
+		Expect(out.String()).To(ContainSubstring("out-2\n"))
+	})
+
+	It("should not consume the input after a remote command", func() {
+		// Given
+		input, writer, err := os.Pipe()
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(input.Close)
+		DeferCleanup(writer.Close)
+
+		Expect(sut.SetInput(input)).To(Succeed())
+		sut.Step(demo.S("First"), demo.S("echo out-$((1))"))
+
+		next := demo.NewRun("Next")
+		Expect(next.SetOutput(out)).To(Succeed())
+		Expect(next.SetInput(input)).To(Succeed())
+		next.SetRemoteControl(control)
+		next.Step(demo.S("Second"), demo.S("echo out-$((2))"))
+
+		done := start()
+		send("/next")
+		send("/next")
+		Eventually(done).Should(Receive(BeNil()))
+
+		// When
+		nextDone := make(chan error, 1)
+
+		go func() {
+			nextDone <- next.RunWithOptions(&demo.Options{Immediate: true})
+		}()
+
+		_, err = writer.WriteString("\n\n")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Then
+		Eventually(nextDone).Should(Receive(BeNil()))
+		Expect(out.String()).To(ContainSubstring("out-2\n"))
+	})
+
+	It("should go back and jump to steps", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		sut.Step(nil, demo.S("echo out-$((2))"))
+		sut.Step(nil, demo.S("echo out-$((3))"))
+
+		// When
+		done := start()
+
+		for _, path := range []string{
+			"/next", "/next", // step 1
+			"/back", "/next", "/next", // step 1 again
+			"/jump/3", "/next", "/next", // step 3
+		} {
+			send(path)
+		}
+
+		// Then
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(strings.Count(out.String(), "out-1\n")).To(Equal(2))
+		Expect(out.String()).NotTo(ContainSubstring("out-2\n"))
+		Expect(out.String()).To(ContainSubstring("out-3\n"))
+	})
+
+	It("should report the status", func() {
+		// Given
+		sut.Step(nil, demo.S("echo first"))
+		sut.Step(nil, demo.S("echo second"))
+
+		// When
+		done := start()
+		send("/next")
+		send("/next")
+
+		// Then
+		Eventually(status).Should(And(
+			HaveKeyWithValue("title", "Remote"),
+			HaveKeyWithValue("step", BeEquivalentTo(2)),
+			HaveKeyWithValue("steps", BeEquivalentTo(2)),
+			HaveKeyWithValue("waiting", true),
+		))
+
+		send("/next")
+		send("/next")
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should pause and resume the automatic mode", func() {
+		// Given
+		sut.Step(nil, demo.S("echo auto"))
+		opts.Auto = true
+		opts.AutoTimeout = 10 * time.Millisecond
+
+		Expect(post("/pause")).To(Equal(http.StatusOK))
+
+		// When
+		done := start()
+
+		// Then
+		Consistently(done, 300*time.Millisecond).ShouldNot(Receive())
+		Expect(status()).To(HaveKeyWithValue("paused", true))
+
+		Expect(post("/resume")).To(Equal(http.StatusOK))
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(out.String()).To(ContainSubstring("auto\n"))
+	})
+
+	It("should stream status events", func() {
+		// Given
+		sut.Step(nil, demo.S("echo streamed"))
+
+		conn, err := websocket.Dial("ws://"+control.Addr()+"/events", "", "http://localhost/")
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		event := map[string]any{}
+		Expect(websocket.JSON.Receive(conn, &event)).To(Succeed())
+		Expect(event).To(HaveKeyWithValue("step", BeEquivalentTo(0)))
+
+		// When
+		done := start()
+
+		// Then
+		Expect(websocket.JSON.Receive(conn, &event)).To(Succeed())
+		Expect(event).To(HaveKeyWithValue("title", "Remote"))
+		Expect(event).To(HaveKeyWithValue("step", BeEquivalentTo(1)))
+
+		send("/next")
+		send("/next")
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should reject commands without the control header", func() {
+		// Given
+		// When
+		resp, err := http.Post("http://"+control.Addr()+"/next", "application/x-www-form-urlencoded", http.NoBody)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		// Then
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(status()).To(HaveKeyWithValue("paused", false))
+		Expect(post("/next")).To(Equal(http.StatusOK))
+	})
+
+	It("should reject invalid step numbers", func() {
+		// Given
+		// When
+		code := post("/jump/foo")
+
+		// Then
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should reject further commands while one is pending", func() {
+		// Given
+		Expect(post("/next")).To(Equal(http.StatusOK))
+
+		// When
+		code := post("/next")
+
+		// Then
+		Expect(code).To(Equal(http.StatusConflict))
+	})
+
+	It("should fail to listen on an invalid address", func() {
+		// Given
+		// When
+		_, err := demo.NewRemoteControl("127.0.0.1:-1")
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("listen on 127.0.0.1:-1")))
+	})
+})
@@ -2,10 +2,12 @@ package demo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/saschagrunert/ccli/v3"
@@ -17,14 +19,18 @@ import (
 type Demo struct {
 	*cli.Command
 
-	runs    []*runFlag
-	setup   func(context.Context, *cli.Command) error
-	cleanup func(context.Context, *cli.Command) error
+	runs      []*runFlag
+	setup     func(context.Context, *cli.Command) error
+	cleanup   func(context.Context, *cli.Command) error
+	data      map[string]any
+	prompt    string
+	observers []Observer
 }
 
 type runFlag struct {
-	run  *Run
-	flag cli.Flag
+	run         *Run
+	flag        cli.Flag
+	description string
 }
 
 const (
@@ -41,6 +47,10 @@ const (
 	// FlagBreakPoint is the flag for doing `auto` but with breakpoint.
 	FlagBreakPoint = "with-breakpoints"
 
+	// FlagCassette is the flag for recording the output of all commands into
+	// a cassette file, which can be replayed later on.
+	FlagCassette = "cassette"
+
 	// FlagContinueOnError is the flag for steps continue running if
 	// there is an error.
 	FlagContinueOnError = "continue-on-error"
@@ -49,24 +59,79 @@ const (
 	// any end.
 	FlagContinuously = "continuously"
 
+	// FlagControlAddr is the flag for serving the remote control HTTP API on
+	// the provided address.
+	FlagControlAddr = "control-addr"
+
 	// FlagDryRun only prints the command in the stdout.
 	FlagDryRun = "dry-run"
 
+	// FlagExportMarkdown is the flag for writing the selected demos as
+	// Markdown tutorial into a file instead of running them.
+	FlagExportMarkdown = "export-markdown"
+
+	// FlagExportOutput is the flag for including the command output in the
+	// Markdown export, which requires executing the commands.
+	FlagExportOutput = "export-output"
+
+	// FlagFile is the flag for loading additional runs from a YAML definition file.
+	FlagFile = "file"
+
 	// FlagHideDescriptions is the flag for hiding the descriptions.
 	FlagHideDescriptions = "hide-descriptions"
 
+	// FlagJSONReport is the flag for the JSON summary path written in `verify` mode.
+	FlagJSONReport = "json-report"
+
+	// FlagJUnitReport is the flag for the JUnit XML report path written in `verify` mode.
+	FlagJUnitReport = "junit-report"
+
 	// FlagImmediate is the flag for disabling the text animations.
 	FlagImmediate = "immediate"
 
+	// FlagMenu is the flag for selecting the demo and the step to start from
+	// in an interactive menu.
+	FlagMenu = "menu"
+
 	// FlagNoColor true to print without colors, special characters for writing into file.
 	FlagNoColor = "no-color"
 
+	// FlagTheme is the flag for selecting a built-in theme or loading one from a file.
+	FlagTheme = "theme"
+
+	// FlagNotesSocket is the flag for the unix socket the speaker notes are
+	// served on and the notes command connects to.
+	FlagNotesSocket = "notes-socket"
+
+	// FlagPreflight is the flag for checking the requirements of all demos
+	// without executing them.
+	FlagPreflight = "preflight"
+
+	// FlagRecord is the flag for recording the demo into an asciinema cast file.
+	FlagRecord = "record"
+
+	// FlagReplay is the flag for replaying the command output from a cassette
+	// file instead of executing the commands.
+	FlagReplay = "replay"
+
 	// FlagSkipSteps is the flag for skipping n amount of steps.
 	FlagSkipSteps = "skip-steps"
 
+	// FlagSet is the flag for setting template data values in the form
+	// `key=value`.
+	FlagSet = "set"
+
 	// FlagShell is the flag for defining the shell that is used to execute the command(s).
 	FlagShell = "shell"
 
+	// FlagValidate is the flag for checking the syntax, executables and
+	// directories of all demos without executing them.
+	FlagValidate = "validate"
+
+	// FlagVerify is the flag for running all demos headlessly to verify
+	// that they still work.
+	FlagVerify = "verify"
+
 	// FlagTypewriterSpeed is the flag for configuring typewriter animation speed (max milliseconds per character).
 	FlagTypewriterSpeed = "typewriter-speed"
 
@@ -92,10 +157,34 @@ func createFlags() []cli.Flag {
 			Value: false,
 			Usage: "run the demo and only prints the commands",
 		},
+		&cli.StringFlag{
+			Name:  FlagExportMarkdown,
+			Usage: "write the selected demos as Markdown tutorial into the provided `file` instead of running them",
+		},
+		&cli.BoolFlag{
+			Name:  FlagExportOutput,
+			Usage: "execute the commands headlessly to include their output when export-markdown is enabled",
+		},
+		&cli.StringFlag{
+			Name:    FlagFile,
+			Aliases: []string{"f"},
+			Usage:   "load and run the demos defined in the provided YAML `file`",
+		},
+		&cli.BoolFlag{
+			Name:    FlagMenu,
+			Aliases: []string{"m"},
+			Usage: "select the demo and the step to start from in an interactive menu, " +
+				"which is the default on terminals if no demo is selected",
+		},
 		&cli.BoolFlag{
 			Name:  FlagNoColor,
 			Usage: "run the demo and output to be without colors",
 		},
+		&cli.StringFlag{
+			Name:  FlagTheme,
+			Usage: "the built-in theme (" + strings.Join(ThemeNames(), ", ") + ") or YAML theme `file` for styling the output",
+			Value: ThemeDefault,
+		},
 		&cli.DurationFlag{
 			Name:    FlagAutoTimeout,
 			Aliases: []string{"t"},
@@ -115,6 +204,10 @@ func createFlags() []cli.Flag {
 			Aliases: []string{"c"},
 			Usage:   "run the demos continuously without any end",
 		},
+		&cli.StringFlag{
+			Name:  FlagControlAddr,
+			Usage: "serve a remote control HTTP and WebSocket API for navigating through the steps on the provided `address`, like 127.0.0.1:8080",
+		},
 		&cli.BoolFlag{
 			Name:    FlagHideDescriptions,
 			Aliases: []string{"d"},
@@ -125,16 +218,61 @@ func createFlags() []cli.Flag {
 			Aliases: []string{"i"},
 			Usage:   "immediately output without the typewriter animation",
 		},
+		&cli.StringFlag{
+			Name:  FlagNotesSocket,
+			Usage: "serve the speaker notes on the provided unix socket `file`, which the notes command connects to",
+		},
+		&cli.BoolFlag{
+			Name: FlagPreflight,
+			Usage: "check the required binaries, environment variables, files and ports of all demos, " +
+				"print a table of them and fail if a requirement is not met",
+		},
+		&cli.StringFlag{
+			Name:  FlagRecord,
+			Usage: "record the demo as asciinema v2 cast into the provided `file`",
+		},
+		&cli.StringFlag{
+			Name:  FlagCassette,
+			Usage: "record the output and exit status of all commands into the provided cassette `file`",
+		},
+		&cli.StringFlag{
+			Name:  FlagReplay,
+			Usage: "replay the command output from the provided cassette `file` instead of executing the commands",
+		},
 		&cli.IntFlag{
 			Name:    FlagSkipSteps,
 			Aliases: []string{"s"},
 			Usage:   "skip the amount of initial steps within the demo",
 		},
+		&cli.StringMapFlag{
+			Name:  FlagSet,
+			Usage: "set a template data value in the form `key=value`, which can be provided multiple times",
+		},
 		&cli.StringFlag{
 			Name:        FlagShell,
 			Usage:       "define the shell that is used to execute the command(s)",
 			DefaultText: "bash",
 		},
+		&cli.BoolFlag{
+			Name: FlagValidate,
+			Usage: "check the command syntax, executables and directories of all demos without executing them, " +
+				"report the result of every step and fail if a step has problems",
+		},
+		&cli.BoolFlag{
+			Name: FlagVerify,
+			Usage: "run all demos headlessly without any animations and timeouts, " +
+				"report the result of every step and fail if a step fails",
+		},
+		&cli.StringFlag{
+			Name:  FlagJUnitReport,
+			Usage: "the JUnit XML report `file` written when `verify` is enabled",
+			Value: "demo-junit.xml",
+		},
+		&cli.StringFlag{
+			Name:  FlagJSONReport,
+			Usage: "the JSON summary `file` written when `verify` is enabled",
+			Value: "demo-report.json",
+		},
 		&cli.IntFlag{
 			Name:  FlagTypewriterSpeed,
 			Usage: "maximum milliseconds per character for typewriter animation",
@@ -143,15 +281,39 @@ func createFlags() []cli.Flag {
 	}
 }
 
-func collectRunFunctions(cmd *cli.Command, runs []*runFlag) []runAction {
-	runFns := make([]runAction, 0, len(runs))
+func collectRuns(cmd *cli.Command, runs []*runFlag) ([]*Run, error) {
+	selected := make([]*Run, 0, len(runs))
 
 	for _, x := range runs {
 		if isFlagSet(cmd, x.flag) || cmd.Bool(FlagAll) {
-			runFns = append(runFns, x.run.Run)
+			selected = append(selected, x.run)
 		}
 	}
 
+	loaded, err := loadFileRuns(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(selected, loaded...), nil
+}
+
+func loadFileRuns(cmd *cli.Command) ([]*Run, error) {
+	path := cmd.String(FlagFile)
+	if path == "" {
+		return nil, nil
+	}
+
+	return LoadRuns(path)
+}
+
+func collectRunFunctions(runs []*Run) []runAction {
+	runFns := make([]runAction, 0, len(runs))
+
+	for _, r := range runs {
+		runFns = append(runFns, r.Run)
+	}
+
 	return runFns
 }
 
@@ -175,6 +337,12 @@ func createRunSelected(demo *Demo, ctx context.Context, cmd *cli.Command, runFns
 			}
 
 			if err := runFn(ctx, cmd); err != nil {
+				if errors.Is(err, ErrQuit) {
+					if cleanupErr := demo.cleanup(ctx, cmd); cleanupErr != nil {
+						return cleanupErr
+					}
+				}
+
 				return err
 			}
 
@@ -210,22 +378,116 @@ func New() *Demo {
 	}
 
 	demo.Flags = createFlags()
+	demo.Commands = []*cli.Command{notesCommand()}
 	demo.UseShortOptionHandling = true
 
 	demo.Action = func(ctx context.Context, cmd *cli.Command) error {
-		runFns := collectRunFunctions(cmd, demo.runs)
-		runSelected := createRunSelected(demo, ctx, cmd, runFns)
+		// Invalid themes fail before any setup function gets called.
+		if _, err := themeFrom(cmd.String(FlagTheme)); err != nil {
+			return err
+		}
+
+		if cmd.Bool(FlagPreflight) {
+			return demo.preflight(ctx, cmd)
+		}
+
+		if cmd.Bool(FlagVerify) {
+			return demo.verify(ctx, cmd)
+		}
+
+		if cmd.Bool(FlagValidate) {
+			return demo.validate(ctx, cmd)
+		}
+
+		runs, err := collectRuns(cmd, demo.runs)
+		if err != nil {
+			return err
+		}
+
+		if cmd.String(FlagExportMarkdown) != "" {
+			demo.inherit(runs)
+
+			return demo.exportMarkdown(ctx, cmd, runs)
+		}
+
+		runSelected := createRunSelected(demo, ctx, cmd, collectRunFunctions(runs))
+
+		if demo.useMenu(cmd, runs) {
+			entries := demo.menuEntries(runs)
+
+			runs = make([]*Run, 0, len(entries))
+			for _, e := range entries {
+				runs = append(runs, e.run)
+			}
+
+			runSelected = func() error { return demo.runMenu(ctx, cmd, entries) }
+		}
+
+		demo.inherit(runs)
+
+		run := func() error {
+			var err error
+			if cmd.Bool(FlagContinuously) {
+				err = runContinuously(ctx, runSelected)
+			} else {
+				err = runSelected()
+			}
+
+			if errors.Is(err, ErrQuit) {
+				return nil
+			}
+
+			return err
+		}
+
+		if path := cmd.String(FlagReplay); path != "" {
+			if err := replayRuns(path, runs); err != nil {
+				return err
+			}
+		}
+
+		if path := cmd.String(FlagNotesSocket); path != "" {
+			runNotes := run
+			run = func() error { return withNotesServer(path, runs, runNotes) }
+		}
 
-		if cmd.Bool(FlagContinuously) {
-			return runContinuously(ctx, runSelected)
+		if address := cmd.String(FlagControlAddr); address != "" {
+			runControlled := run
+			run = func() error { return withRemoteControl(address, runs, runControlled) }
 		}
 
-		return runSelected()
+		if path := cmd.String(FlagCassette); path != "" {
+			runCassette := run
+			run = func() error { return withCassette(path, runs, runCassette) }
+		}
+
+		if path := cmd.String(FlagRecord); path != "" {
+			return withRecording(path, cmd.Root(), runs, run)
+		}
+
+		return run()
 	}
 
 	return demo
 }
 
+// notesCommand returns the command which shows the speaker notes of a demo
+// running in another terminal.
+func notesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "notes",
+		Usage: "show the speaker notes of a demo running with notes-socket in another terminal",
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			path := cmd.String(FlagNotesSocket)
+			if path == "" {
+				return errNoNotesSocket
+			}
+
+			return ShowNotes(ctx, path, cmd.Root().Writer)
+		},
+	}
+}
+
 // Setup sets the setup function called before each run.
 func (d *Demo) Setup(setupFn func(context.Context, *cli.Command) error) {
 	d.setup = setupFn
@@ -236,6 +498,37 @@ func (d *Demo) Cleanup(cleanupFn func(context.Context, *cli.Command) error) {
 	d.cleanup = cleanupFn
 }
 
+// SetData sets a template data value for all runs, which can be referenced
+// via `${{ .Data.key }}`. Values set on a run or provided via the command
+// line take precedence.
+func (d *Demo) SetData(key string, value any) {
+	if d.data == nil {
+		d.data = map[string]any{}
+	}
+
+	d.data[key] = value
+}
+
+// SetPrompt sets the prompt template for all runs which do not have their
+// own one, see Run.SetPrompt.
+func (d *Demo) SetPrompt(prompt string) {
+	d.prompt = prompt
+}
+
+// inherit passes the template data and prompt of the demo to the provided
+// runs.
+func (d *Demo) inherit(runs []*Run) {
+	for _, r := range runs {
+		r.inheritData(d.data)
+
+		if r.prompt == "" {
+			r.SetPrompt(d.prompt)
+		}
+
+		r.demoObservers = d.observers
+	}
+}
+
 // Add registers a new run with the given flag name and description.
 func (d *Demo) Add(run *Run, name, description string) {
 	flag := &cli.BoolFlag{
@@ -244,7 +537,7 @@ func (d *Demo) Add(run *Run, name, description string) {
 	}
 
 	d.Flags = append(d.Flags, flag)
-	d.runs = append(d.runs, &runFlag{run, flag})
+	d.runs = append(d.runs, &runFlag{run, flag, description})
 }
 
 const cleanupTimeout = 10 * time.Second
@@ -3,6 +3,7 @@ package demo_test
 import (
 	"context"
 	"os"
+	"strings"
 	"sync"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -106,6 +107,20 @@ var _ = Describe("Demo", func() {
 		})
 	})
 
+	It("should succeed to run with remote control", func() {
+		withArgs([]string{
+			appName, "--all", "--control-addr=127.0.0.1:0", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			run := demo.NewRun("Title")
+			run.Step(nil, demo.S("echo hello"))
+
+			sut := demo.New()
+			sut.Add(run, "run", "remote controlled run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+	})
+
 	It("should run selected demo by flag", func() {
 		withArgs([]string{
 			appName, "--run2", autoFlag, autoTimeoutFlag, immediateFlag,
@@ -188,4 +203,115 @@ var _ = Describe("Demo", func() {
 			Expect(callCount).To(Equal(3))
 		})
 	})
+	It("should run demos loaded from a definition file", func() {
+		path := writeDefinition(`
+runs:
+  - title: From file
+    steps:
+      - command: echo from file
+`)
+
+		withArgs([]string{
+			appName, "--file", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			sut := demo.New()
+			Expect(sut.RunE()).To(Succeed())
+		})
+	})
+
+	It("should fail to run with an invalid definition file", func() {
+		path := writeDefinition("runs: []")
+
+		withArgs([]string{
+			appName, "--file", path, autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			sut := demo.New()
+			Expect(sut.RunE()).NotTo(Succeed())
+		})
+	})
+
+	It("should succeed to run with a built-in theme", func() {
+		withArgs([]string{
+			appName, "--all", "--theme=high-contrast", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			run := demo.NewRun("Title")
+			run.Step(demo.S("Text"), demo.S("echo hello"))
+
+			sut := demo.New()
+			sut.Add(run, "run", "themed run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+	})
+
+	It("should fail to run with an unknown theme", func() {
+		withArgs([]string{
+			appName, "--all", "--theme=unknown", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			setup := false
+
+			sut := demo.New()
+			sut.Add(demo.NewRun("Title"), "run", "themed run")
+			sut.Setup(func(context.Context, *cli.Command) error {
+				setup = true
+
+				return nil
+			})
+
+			Expect(sut.RunE()).To(MatchError(ContainSubstring(`unknown theme "unknown"`)))
+			Expect(setup).To(BeFalse())
+		})
+	})
+
+	It("should fail to show the notes without socket", func() {
+		withArgs([]string{appName, "notes"}, func() {
+			sut := demo.New()
+			Expect(sut.RunE()).To(MatchError(ContainSubstring("notes command requires --notes-socket")))
+		})
+	})
+
+	It("should expand template data of the demo and the command line", func() {
+		out := &strings.Builder{}
+		r := demo.NewRun("Deploy ${{ .Data.version }}")
+		Expect(r.SetOutput(out)).To(Succeed())
+		r.Step(nil, demo.S("echo ${{ .Data.cluster }}-${{ .Data.version }}"))
+
+		withArgs([]string{
+			appName, "--deploy", "--set", "cluster=production", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			sut := demo.New()
+			sut.SetData("cluster", "staging")
+			sut.SetData("version", "v1.0.0")
+			sut.Add(r, "deploy", "deploy demo")
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		Expect(out.String()).To(ContainSubstring("Deploy v1.0.0"))
+		Expect(out.String()).To(ContainSubstring("production-v1.0.0\n"))
+	})
+
+	It("should use the prompt of the demo for runs without own one", func() {
+		out := &strings.Builder{}
+		inherited := demo.NewRun("Inherited")
+		Expect(inherited.SetOutput(out)).To(Succeed())
+		inherited.Step(nil, demo.S("echo inherited"))
+
+		own := demo.NewRun("Own")
+		Expect(own.SetOutput(out)).To(Succeed())
+		own.SetPrompt("own$ ")
+		own.Step(nil, demo.S("echo own"))
+
+		withArgs([]string{
+			appName, "--all", "--no-color", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			sut := demo.New()
+			sut.SetPrompt("demo$ ")
+			sut.Add(inherited, "inherited", "inherited prompt")
+			sut.Add(own, "own", "own prompt")
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		Expect(out.String()).To(ContainSubstring("demo$ echo inherited\n"))
+		Expect(out.String()).To(ContainSubstring("own$ echo own\n"))
+	})
 })
@@ -0,0 +1,203 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// errExecutorNil is the error returned if no executor has been provided.
+var errExecutorNil = errors.New("provided executor is nil")
+
+const (
+	// defaultShell is the shell used if none has been configured.
+	defaultShell = "bash"
+
+	// outputWaitDelay is the time to wait for the output of an aborted
+	// command, which may still be held open by its child processes.
+	outputWaitDelay = time.Second
+)
+
+// Executor runs the commands of steps and the Ensure helpers. Executors can
+// be plugged into a run via Run.SetExecutor, for example to run the commands
+// within a container, on a remote machine or to fake them in unit tests.
+type Executor interface {
+	// Execute runs the provided command and blocks until it finished. A
+	// non-zero exit status should be returned as error which implements
+	// `ExitCode() int`, like exec.ExitError does.
+	Execute(ctx context.Context, execution *Execution) error
+}
+
+// Execution is a single command to be run by an Executor.
+type Execution struct {
+	// Command is the command line to be interpreted by a shell.
+	Command string
+
+	// Dir is the working directory, empty for the default one.
+	Dir string
+
+	// Env contains additional environment variables in the form "KEY=VALUE".
+	Env []string
+
+	// Stdin is the input of the command, nil for no input.
+	Stdin io.Reader
+
+	// Stdout and Stderr are the outputs of the command, nil to discard them.
+	Stdout, Stderr io.Writer
+
+	// Background indicates a long-running process, which runs until the
+	// context is done. It should be terminated together with all its child
+	// processes, as far as supported by the executor.
+	Background bool
+
+	// TTY indicates that Stdin, Stdout and Stderr are a pseudo-terminal,
+	// which should become the controlling terminal of the command.
+	TTY bool
+}
+
+// LocalExecutor runs commands via a shell on the local machine. It is the
+// default executor of every run.
+type LocalExecutor struct {
+	// Shell is used to execute the command via `-c`, defaults to bash.
+	Shell string
+}
+
+// ContainerExecutor runs commands inside an already running container by
+// using a local container runtime CLI, like `podman exec` or `docker exec`.
+type ContainerExecutor struct {
+	// Runtime is the container runtime CLI, defaults to podman.
+	Runtime string
+
+	// Container is the name or ID of the container.
+	Container string
+
+	// Shell is used within the container to execute the command via `-c`,
+	// defaults to sh.
+	Shell string
+}
+
+// SSHExecutor runs commands on a remote machine by using the local ssh client.
+type SSHExecutor struct {
+	// Destination is the remote machine, like `user@host`.
+	Destination string
+
+	// Args are additional arguments for ssh, like `-p 2222`.
+	Args []string
+
+	// Shell is used on the remote machine to execute the command via `-c`,
+	// defaults to sh.
+	Shell string
+}
+
+// Execute implements the Executor interface.
+func (e *LocalExecutor) Execute(ctx context.Context, execution *Execution) error {
+	shell := e.Shell
+	if shell == "" {
+		shell = defaultShell
+	}
+
+	//nolint:gosec // we purposefully run user-provided code
+	cmd := exec.CommandContext(ctx, shell, "-c", execution.Command)
+	cmd.Dir = execution.Dir
+
+	if len(execution.Env) > 0 {
+		cmd.Env = append(os.Environ(), execution.Env...)
+	}
+
+	if execution.Background {
+		terminateProcessGroup(cmd)
+	}
+
+	if execution.TTY {
+		controllingTerminal(cmd)
+	}
+
+	return runExecution(cmd, execution)
+}
+
+// Execute implements the Executor interface.
+func (e *ContainerExecutor) Execute(ctx context.Context, execution *Execution) error {
+	runtime := e.Runtime
+	if runtime == "" {
+		runtime = "podman"
+	}
+
+	args := []string{"exec"}
+
+	if execution.Stdin != nil {
+		args = append(args, "--interactive")
+	}
+
+	if execution.TTY {
+		args = append(args, "--tty")
+	}
+
+	if execution.Dir != "" {
+		args = append(args, "--workdir", execution.Dir)
+	}
+
+	for _, env := range execution.Env {
+		args = append(args, "--env", env)
+	}
+
+	args = append(args, e.Container, shellOrDefault(e.Shell), "-c", execution.Command)
+
+	//nolint:gosec // we purposefully run user-provided code
+	return runExecution(exec.CommandContext(ctx, runtime, args...), execution)
+}
+
+// Execute implements the Executor interface.
+func (e *SSHExecutor) Execute(ctx context.Context, execution *Execution) error {
+	remote := &strings.Builder{}
+
+	if execution.Dir != "" {
+		remote.WriteString("cd " + shellQuote(execution.Dir) + " && ")
+	}
+
+	if len(execution.Env) > 0 {
+		remote.WriteString("env")
+
+		for _, env := range execution.Env {
+			remote.WriteString(" " + shellQuote(env))
+		}
+
+		remote.WriteString(" ")
+	}
+
+	remote.WriteString(shellOrDefault(e.Shell) + " -c " + shellQuote(execution.Command))
+
+	args := append([]string{}, e.Args...)
+
+	if execution.TTY {
+		args = append(args, "-tt")
+	}
+
+	args = append(args, e.Destination, "--", remote.String())
+
+	//nolint:gosec // we purposefully run user-provided code
+	return runExecution(exec.CommandContext(ctx, "ssh", args...), execution)
+}
+
+// runExecution connects the in- and outputs of the execution to the command
+// and runs it.
+func runExecution(cmd *exec.Cmd, execution *Execution) error {
+	cmd.Stdin = execution.Stdin
+	cmd.Stdout = execution.Stdout
+	cmd.Stderr = execution.Stderr
+	cmd.WaitDelay = outputWaitDelay
+
+	//nolint:wrapcheck // wrapped by the caller
+	return cmd.Run()
+}
+
+func shellOrDefault(shell string) string {
+	if shell == "" {
+		return "sh"
+	}
+
+	return shell
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package demo
+
+import "os/exec"
+
+// terminateProcessGroup is not supported on this platform, where only the
+// command itself gets killed if the context of the command is done.
+func terminateProcessGroup(*exec.Cmd) {}
+
+// controllingTerminal is not supported on this platform.
+func controllingTerminal(*exec.Cmd) {}
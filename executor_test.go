@@ -0,0 +1,170 @@
+package demo_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var errFakeFailed = errors.New("fake failed")
+
+// fakeExecutor records all executions and writes a fixed output.
+type fakeExecutor struct {
+	executions []demo.Execution
+	output     string
+	err        error
+}
+
+func (f *fakeExecutor) Execute(_ context.Context, execution *demo.Execution) error {
+	f.executions = append(f.executions, *execution)
+
+	if execution.Stdout != nil {
+		if _, err := execution.Stdout.Write([]byte(f.output)); err != nil {
+			return err
+		}
+	}
+
+	return f.err
+}
+
+var _ = Describe("Executor", func() {
+	It("should fail to set a nil executor", func() {
+		// Given
+		sut := demo.NewRun("Executor")
+
+		// When
+		err := sut.SetExecutor(nil)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should run steps with a custom executor", func() {
+		// Given
+		executor := &fakeExecutor{output: "faked output\n"}
+		out := &strings.Builder{}
+
+		sut := demo.NewRun("Executor")
+		sut.SetWorkDir("/work")
+		sut.SetEnv("FAKE=1")
+		sut.Step(nil, demo.S("echo", "real"))
+		Expect(sut.SetOutput(out)).To(Succeed())
+		Expect(sut.SetExecutor(executor)).To(Succeed())
+
+		// When
+		err := sut.RunWithOptions(&demo.Options{Auto: true, Immediate: true})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(executor.executions).To(HaveLen(1))
+		Expect(executor.executions[0].Command).To(Equal("echo real"))
+		Expect(executor.executions[0].Dir).To(Equal("/work"))
+		Expect(executor.executions[0].Env).To(ConsistOf("FAKE=1"))
+		Expect(out.String()).To(ContainSubstring("faked output"))
+	})
+
+	It("should fail the step if the executor fails", func() {
+		// Given
+		sut := demo.NewRun("Executor")
+		sut.Step(nil, demo.S("anything"))
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(sut.SetExecutor(&fakeExecutor{err: errFakeFailed})).To(Succeed())
+
+		// When
+		err := sut.RunWithOptions(&demo.Options{Auto: true, Immediate: true})
+
+		// Then
+		Expect(err).To(MatchError(errFakeFailed))
+	})
+
+	It("should ensure commands with a custom executor", func() {
+		// Given
+		executor := &fakeExecutor{}
+
+		// When
+		err := demo.EnsureWithExecutor(context.Background(), executor, "first", "second")
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(executor.executions).To(HaveLen(2))
+		Expect(executor.executions[1].Command).To(Equal("second"))
+	})
+
+	It("should fail to ensure commands without executor", func() {
+		// Given
+		// When
+		err := demo.EnsureWithExecutor(context.Background(), nil, "first")
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should run commands with the local executor", func() {
+		// Given
+		out := &strings.Builder{}
+		sut := &demo.LocalExecutor{Shell: "sh"}
+
+		// When
+		err := sut.Execute(context.Background(), &demo.Execution{
+			Command: "echo $LOCAL_VAR $(pwd)",
+			Dir:     "/tmp",
+			Env:     []string{"LOCAL_VAR=local"},
+			Stdout:  out,
+		})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("local /tmp\n"))
+	})
+
+	It("should run commands with the container executor", func() {
+		// Given
+		out := &strings.Builder{}
+		sut := &demo.ContainerExecutor{Runtime: "echo", Container: "ctr"}
+
+		// When
+		err := sut.Execute(context.Background(), &demo.Execution{
+			Command: "ls",
+			Dir:     "/srv",
+			Env:     []string{"A=B"},
+			Stdin:   strings.NewReader(""),
+			Stdout:  out,
+		})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("exec --interactive --workdir /srv --env A=B ctr sh -c ls\n"))
+	})
+
+	It("should run commands with the ssh executor", func() {
+		// Given
+		bin := GinkgoT().TempDir()
+		Expect(os.WriteFile(
+			filepath.Join(bin, "ssh"), []byte("#!/bin/sh\necho \"$@\"\n"), 0o700,
+		)).To(Succeed())
+		GinkgoT().Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		out := &strings.Builder{}
+		sut := &demo.SSHExecutor{Destination: "user@localhost", Args: []string{"-p", "2222"}}
+
+		// When
+		err := sut.Execute(context.Background(), &demo.Execution{
+			Command: "echo 'hi'",
+			Dir:     "/srv",
+			Env:     []string{"A=B"},
+			Stdout:  out,
+		})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal(
+			`-p 2222 user@localhost -- cd '/srv' && env 'A=B' sh -c 'echo '\''hi'\'''` + "\n",
+		))
+	})
+})
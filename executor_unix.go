@@ -0,0 +1,24 @@
+//go:build unix
+
+package demo
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// terminateProcessGroup starts the command in a new process group, which gets
+// terminated as a whole if the context of the command is done.
+func terminateProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		//nolint:wrapcheck // handled by exec.Cmd
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
+
+// controllingTerminal starts the command in a new session with its standard
+// input as controlling terminal.
+func controllingTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
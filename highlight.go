@@ -0,0 +1,382 @@
+package demo
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// SyntaxTheme defines the colors used for highlighting the displayed
+// commands. A nil color keeps the corresponding text unstyled.
+type SyntaxTheme struct {
+	// Command is used for command names, like `echo` or `kubectl`.
+	Command *color.Color
+
+	// Flag is used for arguments starting with a dash, like `--all`.
+	Flag *color.Color
+
+	// String is used for single and double quoted strings.
+	String *color.Color
+
+	// Variable is used for variable references and assignments, like
+	// `$HOME` or `FOO=bar`.
+	Variable *color.Color
+
+	// Operator is used for pipes, redirections, command lists and
+	// subshells, like `|`, `>`, `&&` or `$(`.
+	Operator *color.Color
+
+	// Comment is used for comments, like `# explanation`.
+	Comment *color.Color
+
+	// Text is used for all other arguments.
+	Text *color.Color
+}
+
+// DefaultSyntaxTheme returns the default theme for highlighting commands.
+func DefaultSyntaxTheme() *SyntaxTheme {
+	return &SyntaxTheme{
+		Command:  color.New(color.FgGreen, color.Bold),
+		Flag:     color.New(color.FgCyan),
+		String:   color.New(color.FgYellow),
+		Variable: color.New(color.FgMagenta),
+		Operator: color.New(color.FgRed),
+		Comment:  color.New(color.FgWhite, color.Faint),
+		Text:     color.New(color.FgGreen),
+	}
+}
+
+// MonochromeSyntaxTheme returns a theme for highlighting commands without
+// colors, which only emphasizes command names and dims comments.
+func MonochromeSyntaxTheme() *SyntaxTheme {
+	return &SyntaxTheme{
+		Command: color.New(color.Bold),
+		Comment: color.New(color.Faint),
+	}
+}
+
+// SetSyntaxTheme sets the theme for highlighting the displayed commands of
+// the run, which takes precedence over the command styles of the Theme
+// option.
+func (r *Run) SetSyntaxTheme(theme *SyntaxTheme) {
+	r.syntaxTheme = theme
+}
+
+// highlight returns the command with syntax highlighting applied, or as is
+// if colors are disabled.
+func (r *Run) highlight(command string) string {
+	if r.options.NoColor {
+		return command
+	}
+
+	theme := r.syntaxTheme
+	if theme == nil {
+		theme = r.theme().Command
+	}
+
+	if theme == nil {
+		return command
+	}
+
+	b := &strings.Builder{}
+
+	for _, t := range tokenize(command) {
+		c := theme.color(t.kind)
+		if c == nil || strings.TrimSpace(t.text) == "" {
+			b.WriteString(t.text)
+
+			continue
+		}
+
+		b.WriteString(c.Sprint(t.text))
+	}
+
+	return b.String()
+}
+
+// color returns the color of the provided token kind.
+func (t *SyntaxTheme) color(kind tokenKind) *color.Color {
+	switch kind {
+	case tokenCommand:
+		return t.Command
+	case tokenFlag:
+		return t.Flag
+	case tokenString:
+		return t.String
+	case tokenVariable:
+		return t.Variable
+	case tokenOperator:
+		return t.Operator
+	case tokenComment:
+		return t.Comment
+	case tokenText:
+		return t.Text
+	}
+
+	return nil
+}
+
+// tokenKind is the syntactic category of a token.
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenCommand
+	tokenFlag
+	tokenString
+	tokenVariable
+	tokenOperator
+	tokenComment
+)
+
+// token is a part of a command line with its syntactic category.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// operators are the shell operators ordered by length, so that the longest
+// one matches first.
+//
+//nolint:gochecknoglobals // constant lookup table
+var operators = []string{
+	"&&", "||", ";;", ">>", ">&", "<<", "&>", "|&", "$(",
+	"|", "&", ";", ">", "<", "(", ")", "`",
+}
+
+// tokenizer splits a shell command line into tokens. It does not aim to be a
+// complete shell parser, but covers the commonly displayed constructs.
+type tokenizer struct {
+	line   string
+	pos    int
+	tokens []token
+
+	// commandPosition is true if the next word is a command name.
+	commandPosition bool
+}
+
+// tokenize splits the command line into tokens.
+func tokenize(line string) []token {
+	t := &tokenizer{line: line, commandPosition: true}
+
+	for t.pos < len(t.line) {
+		t.next()
+	}
+
+	return t.tokens
+}
+
+func (t *tokenizer) add(kind tokenKind, text string) {
+	if text != "" {
+		t.tokens = append(t.tokens, token{kind: kind, text: text})
+	}
+}
+
+// next scans the token at the current position.
+func (t *tokenizer) next() {
+	rest := t.line[t.pos:]
+
+	switch {
+	case rest[0] == ' ' || rest[0] == '\t':
+		end := len(rest) - len(strings.TrimLeft(rest, " \t"))
+		t.add(tokenText, rest[:end])
+		t.pos += end
+	case strings.HasPrefix(rest, "\\\n"):
+		// Line continuations do not end the command.
+		t.add(tokenText, rest[:2])
+		t.pos += 2
+	case rest[0] == '\n':
+		t.add(tokenText, rest[:1])
+		t.pos++
+		t.commandPosition = true
+	case rest[0] == '#':
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+
+		t.add(tokenComment, rest[:end])
+		t.pos += end
+	default:
+		if op := operatorAt(rest); op != "" {
+			t.add(tokenOperator, op)
+			t.pos += len(op)
+
+			// Redirections are followed by a file name and closing subshells
+			// by further arguments instead of a command.
+			if !strings.ContainsAny(op, "<>)") {
+				t.commandPosition = true
+			}
+
+			return
+		}
+
+		t.word()
+	}
+}
+
+// word scans a single word, which consists of unquoted text, quoted strings
+// and variables.
+func (t *tokenizer) word() {
+	kind := t.wordKind()
+	plain := t.pos
+
+	flush := func() {
+		t.add(kind, t.line[plain:t.pos])
+	}
+
+	for t.pos < len(t.line) {
+		c := t.line[t.pos]
+
+		if c == ' ' || c == '\t' || c == '\n' || operatorAt(t.line[t.pos:]) != "" {
+			break
+		}
+
+		switch c {
+		case '\\':
+			t.pos = min(t.pos+2, len(t.line))
+
+			continue
+		case '\'':
+			flush()
+			t.singleQuoted()
+		case '"':
+			flush()
+			t.doubleQuoted()
+		case '$':
+			flush()
+			t.variable()
+		default:
+			t.pos++
+
+			continue
+		}
+
+		plain = t.pos
+	}
+
+	flush()
+
+	// Assignments in front of a command keep the command position.
+	if kind != tokenVariable {
+		t.commandPosition = false
+	}
+}
+
+// wordKind returns the kind of the word at the current position.
+func (t *tokenizer) wordKind() tokenKind {
+	rest := t.line[t.pos:]
+
+	switch {
+	case t.commandPosition && isAssignment(rest):
+		return tokenVariable
+	case t.commandPosition:
+		return tokenCommand
+	case strings.HasPrefix(rest, "-"):
+		return tokenFlag
+	default:
+		return tokenText
+	}
+}
+
+// singleQuoted scans a single quoted string.
+func (t *tokenizer) singleQuoted() {
+	end := strings.IndexByte(t.line[t.pos+1:], '\'')
+	if end < 0 {
+		t.add(tokenString, t.line[t.pos:])
+		t.pos = len(t.line)
+
+		return
+	}
+
+	end += t.pos + 2
+	t.add(tokenString, t.line[t.pos:end])
+	t.pos = end
+}
+
+// doubleQuoted scans a double quoted string, which may contain variables.
+func (t *tokenizer) doubleQuoted() {
+	plain := t.pos
+	t.pos++
+
+	for t.pos < len(t.line) {
+		switch t.line[t.pos] {
+		case '\\':
+			t.pos = min(t.pos+2, len(t.line))
+		case '"':
+			t.pos++
+			t.add(tokenString, t.line[plain:t.pos])
+
+			return
+		case '$':
+			if strings.HasPrefix(t.line[t.pos:], "$(") {
+				t.pos++
+
+				continue
+			}
+
+			t.add(tokenString, t.line[plain:t.pos])
+			t.variable()
+			plain = t.pos
+		default:
+			t.pos++
+		}
+	}
+
+	t.add(tokenString, t.line[plain:])
+}
+
+// variable scans a variable reference, like `$HOME`, `${HOME}` or `$?`.
+func (t *tokenizer) variable() {
+	rest := t.line[t.pos:]
+	end := 1
+
+	switch {
+	case strings.HasPrefix(rest, "${"):
+		end = strings.IndexByte(rest, '}') + 1
+		if end == 0 {
+			end = len(rest)
+		}
+	case len(rest) > 1 && strings.IndexByte("?@#$!*-0123456789", rest[1]) >= 0:
+		end = 2
+	default:
+		for end < len(rest) && isNameChar(rest[end]) {
+			end++
+		}
+	}
+
+	t.add(tokenVariable, rest[:end])
+	t.pos += end
+}
+
+// operatorAt returns the operator at the beginning of the text, if any.
+func operatorAt(text string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(text, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+// isAssignment returns true if the text starts with a variable assignment,
+// like `FOO=bar`.
+func isAssignment(text string) bool {
+	name, _, ok := strings.Cut(text, "=")
+	if !ok || name == "" {
+		return false
+	}
+
+	for i := range len(name) {
+		if !isNameChar(name[i]) || (i == 0 && name[i] >= '0' && name[i] <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,123 @@
+package demo_test
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Highlighting", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	colored := func(attribute color.Attribute) *color.Color {
+		c := color.New(attribute)
+		c.EnableColor()
+
+		return c
+	}
+
+	// style returns the text in the provided SGR color code.
+	style := func(code, text string) string {
+		return "\x1b[" + code + "m" + text + "\x1b[0m"
+	}
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		sut.SetSyntaxTheme(&demo.SyntaxTheme{
+			Command:  colored(color.FgRed),
+			Flag:     colored(color.FgGreen),
+			String:   colored(color.FgYellow),
+			Variable: colored(color.FgBlue),
+			Operator: colored(color.FgMagenta),
+			Comment:  colored(color.FgCyan),
+		})
+
+		opts = demo.Options{Auto: true, Immediate: true, DryRun: true}
+	})
+
+	It("should highlight commands, flags, strings, variables, operators and comments", func() {
+		// Given
+		sut.Step(nil, demo.S(`FOO=1 kubectl get pods -n "$NS" | grep 'x' # done`))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> " +
+			style("34", "FOO=1") + " " + style("31", "kubectl") + " get pods " + style("32", "-n") + " " +
+			style("33", `"`) + style("34", "$NS") + style("33", `"`) + " " +
+			style("35", "|") + " " + style("31", "grep") + " " + style("33", "'x'") + " " +
+			style("36", "# done") + "\n",
+		))
+	})
+
+	It("should highlight commands after operators and subshells", func() {
+		// Given
+		sut.Step(nil, demo.S("cd /tmp && echo $(date) 2>&1 > out.txt"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> " +
+			style("31", "cd") + " /tmp " + style("35", "&&") + " " + style("31", "echo") + " " +
+			style("35", "$(") + style("31", "date") + style("35", ")") + " 2" + style("35", ">&") + "1 " +
+			style("35", ">") + " out.txt\n",
+		))
+	})
+
+	It("should highlight multi-line commands", func() {
+		// Given
+		sut.Step(nil, demo.S("echo ${HOME}", "--flag"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> " +
+			style("31", "echo") + " " + style("34", "${HOME}") + " \\\n    " + style("32", "--flag") + "\n",
+		))
+	})
+
+	It("should keep escape sequences intact during the typewriter animation", func() {
+		// Given
+		sut.Step(nil, demo.S("echo -n 'hi'"))
+		opts.Immediate = false
+		opts.TypewriterSpeed = 1
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> " +
+			style("31", "echo") + " " + style("32", "-n") + " " + style("33", "'hi'") + "\n",
+		))
+	})
+
+	It("should not highlight without colors", func() {
+		// Given
+		sut.Step(nil, demo.S(`echo "$HOME" | cat`))
+		opts.NoColor = true
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> echo \"$HOME\" | cat\n"))
+	})
+})
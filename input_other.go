@@ -0,0 +1,15 @@
+//go:build !unix
+
+package demo
+
+import "os"
+
+// inputPollable is false on this platform, where the input can only be read
+// by blocking.
+const inputPollable = false
+
+// inputReady is not supported on this platform and always reports the file
+// as readable.
+func inputReady(*os.File) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// inputPollTimeout is the time in milliseconds to wait for presenter input
+// before checking whether the wait should stop.
+const inputPollTimeout = 50
+
+// inputPollable is true if inputReady is able to wait for input without
+// consuming it.
+const inputPollable = true
+
+// inputReady waits up to inputPollTimeout for the file to become readable.
+// A closed input is readable as well, where the next read returns io.EOF.
+func inputReady(f *os.File) (bool, error) {
+	//nolint:gosec // file descriptors fit into int32
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLIN}}
+
+	n, err := unix.Poll(fds, inputPollTimeout)
+	if errors.Is(err, unix.EINTR) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("poll input: %w", err)
+	}
+
+	return n > 0, nil
+}
@@ -0,0 +1,144 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+)
+
+// errExpectNotFound is the error returned if the expected output of an
+// interaction did not appear.
+var errExpectNotFound = errors.New("expected output not found")
+
+const (
+	// defaultExpectTimeout is the time to wait for the expected output of an
+	// interaction if it has no timeout.
+	defaultExpectTimeout = 30 * time.Second
+
+	// expectPollInterval is the time between two checks of the output.
+	expectPollInterval = 20 * time.Millisecond
+)
+
+// Interaction is a single expect/send pair of the script of StepInteract.
+type Interaction struct {
+	// Expect is a regular expression which has to match the output of the
+	// command since the previous interaction before Send gets typed. An
+	// empty expectation sends immediately.
+	Expect string
+
+	// Send is typed into the command as is, use "\n" to press Enter.
+	Send string
+
+	// Timeout is the maximum time to wait for the expected output, which
+	// defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// StepInteract creates a new step which runs the command attached to a
+// pseudo-terminal and drives it by the provided script, like answering a
+// prompt or using a REPL. The input is typed with the typewriter animation,
+// so that the audience sees the interaction as echoed by the terminal.
+//
+// The output is matched against the raw terminal output, which may contain
+// "\r\n" line endings and escape sequences. The presenter input is not
+// forwarded to scripted commands.
+func (r *Run) StepInteract(text, command []string, script []Interaction, opts ...StepOption) {
+	r.steps = append(r.steps, step{text: text, command: command, script: script}.applyOptions(opts))
+}
+
+// interact runs the script by waiting for the expected output and typing the
+// input until the script finished, the context is done or the command exited.
+func (r *Run) interact(
+	ctx context.Context, script []Interaction, input io.Writer, output *syncBuffer, exited <-chan struct{},
+) error {
+	offset := 0
+
+	for _, interaction := range script {
+		end, err := waitForOutput(ctx, interaction, output, offset, exited)
+		if err != nil {
+			return err
+		}
+
+		offset = end
+
+		if err := r.typeInput(ctx, input, interaction.Send); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForOutput polls the output after the offset until it matches the
+// expectation and returns the end of the match.
+func waitForOutput(
+	ctx context.Context, interaction Interaction, output *syncBuffer, offset int, exited <-chan struct{},
+) (int, error) {
+	if interaction.Expect == "" {
+		return offset, nil
+	}
+
+	re, err := regexp.Compile(interaction.Expect)
+	if err != nil {
+		return 0, fmt.Errorf("compile expected output: %w", err)
+	}
+
+	timeout := interaction.Timeout
+	if timeout <= 0 {
+		timeout = defaultExpectTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(expectPollInterval)
+	defer ticker.Stop()
+
+	for {
+		data := output.String()[offset:]
+		if loc := re.FindStringIndex(data); loc != nil {
+			return offset + loc[1], nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("wait for expected output: %w", ctx.Err())
+		case <-exited:
+			// The output is complete once the command exited.
+			data = output.String()[offset:]
+			if loc := re.FindStringIndex(data); loc != nil {
+				return offset + loc[1], nil
+			}
+
+			return 0, fmt.Errorf("%w: command exited before output matched %q:\n%s",
+				errExpectNotFound, interaction.Expect, indent(data))
+		case <-timer.C:
+			return 0, fmt.Errorf("%w: output did not match %q within %s:\n%s",
+				errExpectNotFound, interaction.Expect, timeout, indent(data))
+		case <-ticker.C:
+		}
+	}
+}
+
+// typeInput writes the input with the typewriter animation, or at once in
+// immediate mode.
+func (r *Run) typeInput(ctx context.Context, input io.Writer, text string) error {
+	if r.options.Immediate {
+		return write(input, text)
+	}
+
+	for _, c := range text {
+		if err := sleep(ctx, r.typewriterDelay()); err != nil {
+			return err
+		}
+
+		if err := write(input, string(c)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package demo_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("StepInteract", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should succeed to answer a prompt", func() {
+		// Given
+		sut.StepInteract(nil, demo.S("read -p 'Continue? [y/N] ' a; echo answer-$a"), []demo.Interaction{
+			{Expect: `\[y/N\]`, Send: "y\n"},
+		})
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("Continue? [y/N] y\r\n"))
+		Expect(out.String()).To(ContainSubstring("answer-y\r\n"))
+	})
+
+	It("should succeed to drive a REPL", func() {
+		// Given
+		sut.StepInteract(nil, demo.S(`while read -p '> ' l; do [ "$l" = quit ] && break; echo "=$l"; done`),
+			[]demo.Interaction{
+				{Expect: "> ", Send: "one\n"},
+				{Expect: "=one\r\n> ", Send: "two\n"},
+				{Expect: "=two\r\n> ", Send: "quit\n"},
+			},
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("> two\r\n=two\r\n> quit\r\n"))
+	})
+
+	It("should type the input with the typewriter animation", func() {
+		// Given
+		sut.StepInteract(nil, demo.S("read a; echo answer-$a"), []demo.Interaction{
+			{Send: "typed\n"},
+		})
+		opts.Immediate = false
+		opts.TypewriterSpeed = 20
+
+		// When
+		start := time.Now()
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("answer-typed\r\n"))
+		Expect(time.Since(start)).To(BeNumerically(">", 20*time.Millisecond))
+	})
+
+	It("should fail if the expected output does not appear in time", func() {
+		// Given
+		sut.StepInteract(nil, demo.S("echo waiting; sleep 30"), []demo.Interaction{
+			{Expect: "never", Timeout: 200 * time.Millisecond},
+		})
+
+		// When
+		start := time.Now()
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`expected output not found: output did not match "never" within 200ms`)))
+		Expect(err).To(MatchError(ContainSubstring("  waiting")))
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+	})
+
+	It("should fail if the command exits before the expected output", func() {
+		// Given
+		sut.StepInteract(nil, demo.S("echo bye"), []demo.Interaction{
+			{Expect: "never"},
+		})
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`command exited before output matched "never"`)))
+	})
+
+	It("should not continue if the script fails", func() {
+		// Given
+		sut.StepInteract(nil, demo.S("true"), []demo.Interaction{{Expect: "never"}}, demo.WithTimeout(time.Second))
+		sut.Step(nil, demo.S("echo next"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(out.String()).NotTo(ContainSubstring("next\n"))
+	})
+})
@@ -0,0 +1,571 @@
+package demo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	// errNoRuns is the error returned if a definition file contains no runs.
+	errNoRuns = errors.New("no runs defined")
+
+	// errUnknownField is the error returned for unsupported definition keys.
+	errUnknownField = errors.New("unknown field")
+
+	// errExpectedMapping is the error returned if a run or step is not a mapping.
+	errExpectedMapping = errors.New("expected a mapping")
+
+	// errEmptyStep is the error returned for steps without any content.
+	errEmptyStep = errors.New("step needs a text, command, chdir or breakPoint")
+
+	// errConflictingStep is the error returned for chdir or breakpoint steps
+	// which define additional fields.
+	errConflictingStep = errors.New("chdir and breakPoint steps cannot be combined with other fields")
+
+	// errCaptureName is the error returned for a capture pattern without
+	// variable name.
+	errCaptureName = errors.New("capturePattern requires a capture variable name")
+
+	// errInvalidProbe is the error returned for readiness probes of regular
+	// steps or multiple probes of a background step.
+	errInvalidProbe = errors.New("a single readyLog, readyPort, readyFile or readyHTTP requires background")
+
+	// errInvalidExpect is the error returned for output expectations of steps
+	// without command, background steps or multiple expectations of a step.
+	errInvalidExpect = errors.New("a single expect, expectContains or expectMatches requires a command without background")
+
+	// errBackgroundInteract is the error returned for background steps with
+	// an interaction script.
+	errBackgroundInteract = errors.New("background steps cannot be combined with interact")
+
+	// errHookWithoutCommand is the error returned for setup or cleanup
+	// commands of steps without command.
+	errHookWithoutCommand = errors.New("setup and cleanup require a command")
+
+	// errInvalidRequirement is the error returned for requirements which do
+	// not define exactly one kind or version fields without binary.
+	errInvalidRequirement = errors.New("a requirement needs exactly one of binary, env, file, portFree or portOpen")
+
+	// errInvalidEnv is the error returned for environment variables not in
+	// the form "KEY=VALUE".
+	errInvalidEnv = errors.New("environment variable must be in the form KEY=VALUE")
+)
+
+// definition is the top level structure of a YAML run definition file.
+type definition struct {
+	Runs []runDefinition `yaml:"runs"`
+}
+
+type runDefinition struct {
+	Title       string           `yaml:"title"`
+	Description lines            `yaml:"description"`
+	WorkDir     string           `yaml:"workDir"`
+	Env         []envVar         `yaml:"env"`
+	Session     bool             `yaml:"session"`
+	Data        map[string]any   `yaml:"data"`
+	PTY         bool             `yaml:"pty"`
+	Notes       lines            `yaml:"notes"`
+	Prompt      string           `yaml:"prompt"`
+	Require     []requirement    `yaml:"require"`
+	Steps       []stepDefinition `yaml:"steps"`
+}
+
+type stepDefinition struct {
+	Text       lines         `yaml:"text"`
+	Command    lines         `yaml:"command"`
+	CanFail    bool          `yaml:"canFail"`
+	BreakPoint bool          `yaml:"breakPoint"`
+	Chdir      string        `yaml:"chdir"`
+	Timeout    time.Duration `yaml:"timeout"`
+	Retries    int           `yaml:"retries"`
+	Backoff    time.Duration `yaml:"backoff"`
+	Eventually time.Duration `yaml:"eventually"`
+	Interval   time.Duration `yaml:"interval"`
+	Capture    string        `yaml:"capture"`
+	Pattern    string        `yaml:"capturePattern"`
+	Background bool          `yaml:"background"`
+	HideOutput bool          `yaml:"hideOutput"`
+	ReadyLog   string        `yaml:"readyLog"`
+	ReadyPort  string        `yaml:"readyPort"`
+	ReadyFile  string        `yaml:"readyFile"`
+	ReadyHTTP  string        `yaml:"readyHTTP"`
+	PTY        bool          `yaml:"pty"`
+	Interact   []interaction `yaml:"interact"`
+	Notes      lines         `yaml:"notes"`
+	Setup      lines         `yaml:"setup"`
+	Cleanup    lines         `yaml:"cleanup"`
+	Expect     string        `yaml:"expect"`
+	Contains   string        `yaml:"expectContains"`
+	Matches    string        `yaml:"expectMatches"`
+}
+
+// interaction is a single expect/send pair of an interaction script.
+type interaction struct {
+	Expect  string        `yaml:"expect"`
+	Send    string        `yaml:"send"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type requirement struct {
+	Binary         string `yaml:"binary"`
+	MinVersion     string `yaml:"minVersion"`
+	VersionArgs    lines  `yaml:"versionArgs"`
+	VersionPattern string `yaml:"versionPattern"`
+	Env            string `yaml:"env"`
+	File           string `yaml:"file"`
+	PortFree       string `yaml:"portFree"`
+	PortOpen       string `yaml:"portOpen"`
+}
+
+// lines is a list of strings which can be written as a single scalar too.
+type lines []string
+
+// envVar is a single "KEY=VALUE" environment variable.
+type envVar string
+
+// LoadRuns parses the YAML definition file at the provided path into runs.
+//
+// A definition file looks like this:
+//
+//	runs:
+//	  - title: Demo Title
+//	    description:
+//	      - Some additional
+//	      - multi-line description
+//	    workDir: /tmp
+//	    env:
+//	      - MY_VAR=hello
+//	    session: false
+//	    data:
+//	      version: v1.2.3
+//	    notes: Private speaker notes
+//	    prompt: '{{.User}}@{{.Host}}:{{.Dir}}$ '
+//	    require:
+//	      - binary: podman
+//	        minVersion: "4.0"
+//	      - binary: kubectl
+//	        minVersion: "1.28"
+//	        versionArgs: [version, --client]
+//	      - env: KUBECONFIG
+//	      - file: /etc/hosts
+//	      - portFree: :8080
+//	    steps:
+//	      - text: Show the variable
+//	        command: echo $MY_VAR
+//	        notes:
+//	          - Mention that the variable
+//	          - comes from the env field
+//	      - command: exit 1
+//	        canFail: true
+//	      - command: uname
+//	        expect: Linux
+//	      - command: podman --version
+//	        expectMatches: 'podman version \d+'
+//	      - command: curl -sf localhost:8080
+//	        timeout: 5s
+//	        eventually: 1m
+//	        interval: 2s
+//	      - command: ./flaky.sh
+//	        retries: 3
+//	        backoff: 1s
+//	      - command: podman run -d --name demo alpine sleep 100
+//	        capture: id
+//	        setup: podman rm -f demo
+//	        cleanup:
+//	          - podman rm -f demo
+//	      - command: podman logs ${{ .Vars.id }}
+//	      - command: python3 -m http.server 8080
+//	        background: true
+//	        readyHTTP: http://localhost:8080
+//	      - command: read -p 'Continue? [y/N] ' answer
+//	        interact:
+//	          - expect: '\[y/N\]'
+//	            send: "y\n"
+//	            timeout: 10s
+//	      - breakPoint: true
+//	      - chdir: /home
+//
+// Validation errors contain the file name and the line of the offending
+// definition.
+func LoadRuns(path string) ([]*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read run definition: %w", err)
+	}
+
+	runs, err := parseRuns(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return runs, nil
+}
+
+func parseRuns(data []byte) ([]*Run, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var def definition
+	if err := decoder.Decode(&def); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse run definition: %w", err)
+	}
+
+	if len(def.Runs) == 0 {
+		return nil, errNoRuns
+	}
+
+	runs := make([]*Run, 0, len(def.Runs))
+	for i := range def.Runs {
+		runs = append(runs, def.Runs[i].build())
+	}
+
+	return runs, nil
+}
+
+// UnmarshalYAML decodes and validates a single run definition.
+func (d *runDefinition) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkKeys(
+		node, "title", "description", "workDir", "env", "session", "data", "pty", "notes", "prompt", "require", "steps",
+	); err != nil {
+		return err
+	}
+
+	type plain runDefinition
+
+	//nolint:wrapcheck // errors already contain the line number
+	return node.Decode((*plain)(d))
+}
+
+func (d *runDefinition) build() *Run {
+	r := NewRun(d.Title, d.Description...)
+	r.SetWorkDir(d.WorkDir)
+	r.SetSession(d.Session)
+	r.SetPTY(d.PTY)
+	r.SetNotes(d.Notes...)
+	r.SetPrompt(d.Prompt)
+
+	for _, e := range d.Env {
+		r.SetEnv(string(e))
+	}
+
+	for key, value := range d.Data {
+		r.SetData(key, value)
+	}
+
+	for i := range d.Require {
+		r.Require(d.Require[i].build())
+	}
+
+	for _, s := range d.Steps {
+		switch {
+		case s.BreakPoint:
+			r.BreakPoint()
+		case s.Chdir != "":
+			r.Chdir(s.Chdir)
+		case s.Background:
+			opts := s.options()
+			if s.CanFail {
+				opts = append(opts, canFail())
+			}
+
+			r.StepBackground(s.Text, s.Command, s.probe(), opts...)
+		case len(s.Interact) > 0:
+			opts := s.options()
+			if s.CanFail {
+				opts = append(opts, canFail())
+			}
+
+			r.StepInteract(s.Text, s.Command, s.script(), opts...)
+		case s.CanFail:
+			r.StepCanFail(s.Text, s.Command, s.options()...)
+		default:
+			r.Step(s.Text, s.Command, s.options()...)
+		}
+	}
+
+	return r
+}
+
+// UnmarshalYAML decodes and validates a single step definition.
+func (d *stepDefinition) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkKeys(
+		node, "text", "command", "canFail", "breakPoint", "chdir",
+		"timeout", "retries", "backoff", "eventually", "interval", "capture", "capturePattern",
+		"background", "hideOutput", "readyLog", "readyPort", "readyFile", "readyHTTP", "pty", "interact", "notes",
+		"setup", "cleanup", "expect", "expectContains", "expectMatches",
+	); err != nil {
+		return err
+	}
+
+	type plain stepDefinition
+	if err := node.Decode((*plain)(d)); err != nil {
+		//nolint:wrapcheck // errors already contain the line number
+		return err
+	}
+
+	hasContent := d.hasContent()
+	probes := 0
+
+	for _, probe := range []string{d.ReadyLog, d.ReadyPort, d.ReadyFile, d.ReadyHTTP} {
+		if probe != "" {
+			probes++
+		}
+	}
+
+	expectations := 0
+
+	for _, expectation := range []string{d.Expect, d.Contains, d.Matches} {
+		if expectation != "" {
+			expectations++
+		}
+	}
+
+	switch {
+	case d.BreakPoint && (hasContent || d.Chdir != ""):
+		return fmt.Errorf("line %d: %w", node.Line, errConflictingStep)
+	case d.Chdir != "" && hasContent:
+		return fmt.Errorf("line %d: %w", node.Line, errConflictingStep)
+	case !d.BreakPoint && d.Chdir == "" && len(d.Text) == 0 && len(d.Command) == 0:
+		return fmt.Errorf("line %d: %w", node.Line, errEmptyStep)
+	case d.Pattern != "" && d.Capture == "":
+		return fmt.Errorf("line %d: %w", node.Line, errCaptureName)
+	case probes > 1 || (probes == 1 && !d.Background):
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidProbe)
+	case d.Background && len(d.Interact) > 0:
+		return fmt.Errorf("line %d: %w", node.Line, errBackgroundInteract)
+	case (len(d.Setup) > 0 || len(d.Cleanup) > 0) && len(d.Command) == 0:
+		return fmt.Errorf("line %d: %w", node.Line, errHookWithoutCommand)
+	case expectations > 1 || (expectations == 1 && (len(d.Command) == 0 || d.Background)):
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidExpect)
+	}
+
+	if d.Matches != "" {
+		if _, err := regexp.Compile(d.Matches); err != nil {
+			return fmt.Errorf("line %d: compile expectMatches: %w", node.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// hasContent returns true if any field besides breakPoint and chdir is set.
+func (d *stepDefinition) hasContent() bool {
+	rest := *d
+	rest.BreakPoint = false
+	rest.Chdir = ""
+
+	return !reflect.DeepEqual(rest, stepDefinition{})
+}
+
+// probe returns the readiness probe of a background step definition.
+func (d *stepDefinition) probe() Probe {
+	switch {
+	case d.ReadyLog != "":
+		return WaitForLog(d.ReadyLog)
+	case d.ReadyPort != "":
+		return WaitForPort(d.ReadyPort)
+	case d.ReadyFile != "":
+		return WaitForFile(d.ReadyFile)
+	case d.ReadyHTTP != "":
+		return WaitForHTTP(d.ReadyHTTP)
+	default:
+		return nil
+	}
+}
+
+// matcher returns the output expectation of the step definition.
+func (d *stepDefinition) matcher() Matcher {
+	switch {
+	case d.Expect != "":
+		return OutputEquals(d.Expect)
+	case d.Contains != "":
+		return OutputContains(d.Contains)
+	case d.Matches != "":
+		return OutputMatches(d.Matches)
+	default:
+		return nil
+	}
+}
+
+// script returns the interaction script of the step definition.
+func (d *stepDefinition) script() []Interaction {
+	script := make([]Interaction, 0, len(d.Interact))
+	for _, i := range d.Interact {
+		script = append(script, Interaction(i))
+	}
+
+	return script
+}
+
+// options returns the step options of the definition.
+func (d *stepDefinition) options() []StepOption {
+	var opts []StepOption
+
+	if d.Timeout > 0 {
+		opts = append(opts, WithTimeout(d.Timeout))
+	}
+
+	if d.Retries > 0 {
+		opts = append(opts, WithRetries(d.Retries, d.Backoff))
+	}
+
+	if d.Eventually > 0 {
+		opts = append(opts, Eventually(d.Eventually, d.Interval))
+	}
+
+	if d.HideOutput {
+		opts = append(opts, HideOutput())
+	}
+
+	if d.PTY {
+		opts = append(opts, WithPTY())
+	}
+
+	if len(d.Notes) > 0 {
+		opts = append(opts, WithNotes(d.Notes...))
+	}
+
+	if len(d.Setup) > 0 {
+		opts = append(opts, WithSetupCommands(d.Setup...))
+	}
+
+	if len(d.Cleanup) > 0 {
+		opts = append(opts, WithCleanupCommands(d.Cleanup...))
+	}
+
+	if m := d.matcher(); m != nil {
+		opts = append(opts, withMatcher(m))
+	}
+
+	switch {
+	case d.Pattern != "":
+		opts = append(opts, CaptureMatch(d.Capture, d.Pattern))
+	case d.Capture != "":
+		opts = append(opts, CaptureAs(d.Capture))
+	}
+
+	return opts
+}
+
+// UnmarshalYAML decodes and validates a single interaction.
+func (i *interaction) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkKeys(node, "expect", "send", "timeout"); err != nil {
+		return err
+	}
+
+	type plain interaction
+
+	//nolint:wrapcheck // errors already contain the line number
+	return node.Decode((*plain)(i))
+}
+
+// UnmarshalYAML decodes and validates a single requirement.
+func (q *requirement) UnmarshalYAML(node *yaml.Node) error {
+	if err := checkKeys(
+		node, "binary", "minVersion", "versionArgs", "versionPattern", "env", "file", "portFree", "portOpen",
+	); err != nil {
+		return err
+	}
+
+	type plain requirement
+
+	if err := node.Decode((*plain)(q)); err != nil {
+		//nolint:wrapcheck // errors already contain the line number
+		return err
+	}
+
+	kinds := 0
+
+	for _, value := range []string{q.Binary, q.Env, q.File, q.PortFree, q.PortOpen} {
+		if value != "" {
+			kinds++
+		}
+	}
+
+	hasVersion := q.MinVersion != "" || len(q.VersionArgs) > 0 || q.VersionPattern != ""
+	if kinds != 1 || (hasVersion && q.Binary == "") {
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidRequirement)
+	}
+
+	if _, err := regexp.Compile(q.VersionPattern); err != nil {
+		return fmt.Errorf("line %d: compile version pattern: %w", node.Line, err)
+	}
+
+	return nil
+}
+
+func (q *requirement) build() Requirement {
+	switch {
+	case q.Env != "":
+		return RequireEnv(q.Env)
+	case q.File != "":
+		return RequireFile(q.File)
+	case q.PortFree != "":
+		return RequirePortFree(q.PortFree)
+	case q.PortOpen != "":
+		return RequirePortOpen(q.PortOpen)
+	default:
+		return &BinaryRequirement{
+			Name:           q.Binary,
+			MinVersion:     q.MinVersion,
+			VersionArgs:    q.VersionArgs,
+			VersionPattern: q.VersionPattern,
+		}
+	}
+}
+
+// UnmarshalYAML decodes either a single string or a list of strings.
+func (l *lines) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = lines{node.Value}
+
+		return nil
+	}
+
+	var s []string
+	if err := node.Decode(&s); err != nil {
+		//nolint:wrapcheck // errors already contain the line number
+		return err
+	}
+
+	*l = s
+
+	return nil
+}
+
+// UnmarshalYAML decodes and validates a single environment variable.
+func (e *envVar) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode || !strings.Contains(node.Value, "=") {
+		return fmt.Errorf("line %d: %w", node.Line, errInvalidEnv)
+	}
+
+	*e = envVar(node.Value)
+
+	return nil
+}
+
+// checkKeys verifies that the node is a mapping which only contains allowed keys.
+func checkKeys(node *yaml.Node, allowed ...string) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: %w", node.Line, errExpectedMapping)
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !slices.Contains(allowed, key.Value) {
+			return fmt.Errorf("line %d: %w %q", key.Line, errUnknownField, key.Value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,649 @@
+package demo_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+func writeDefinition(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "demo.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+	return path
+}
+
+var _ = Describe("LoadRuns", func() {
+	var opts demo.Options
+
+	BeforeEach(func() {
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should succeed to load and run a definition", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Loaded Title
+    description:
+      - First line
+      - Second line
+    workDir: /tmp
+    env:
+      - LOADED_VAR=loaded123
+    steps:
+      - text: Print the variable
+        command: echo $LOADED_VAR
+      - command:
+          - pwd
+      - command: exit 1
+        canFail: true
+      - breakPoint: true
+      - chdir: /
+      - text: [Only a description]
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Loaded Title"))
+		Expect(out.String()).To(ContainSubstring("Second line"))
+		Expect(out.String()).To(ContainSubstring("loaded123"))
+		Expect(out.String()).To(ContainSubstring("/tmp"))
+		Expect(out.String()).To(ContainSubstring("cd /"))
+		Expect(out.String()).To(ContainSubstring("[5/5]"))
+	})
+
+	It("should succeed to load a run in session mode", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Session
+    session: true
+    steps:
+      - command: export LOADED_SESSION=kept
+      - command: echo $LOADED_SESSION
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("kept"))
+	})
+
+	It("should succeed to load multiple runs", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: First
+  - title: Second
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(2))
+	})
+
+	It("should succeed to load steps with timeouts and retries", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: sleep 10
+        timeout: 100ms
+        canFail: true
+      - command: exit 1
+        retries: 2
+        backoff: 1ms
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs).To(HaveLen(1))
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(MatchError(ContainSubstring("exit status 1")))
+		Expect(out.String()).To(ContainSubstring("exit status 1, retrying in 1ms"))
+		Expect(out.String()).To(ContainSubstring("exit status 1, retrying in 2ms"))
+	})
+
+	It("should succeed to load steps with captures", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: echo 'id=42'
+        capture: id
+        capturePattern: id=(\d+)
+      - command: echo id-${{ .Vars.id }}
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("id-42\n"))
+	})
+
+	It("should succeed to load a run with template data", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Install ${{ .Data.version }}
+    data:
+      version: v2.0.0
+    steps:
+      - command: echo ${{ .Data.version }}
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Install v2.0.0\n"))
+	})
+
+	It("should succeed to load a background step", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: echo started; sleep 30
+        background: true
+        hideOutput: true
+        readyLog: started
+      - command: echo next
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).NotTo(ContainSubstring("started\n"))
+		Expect(out.String()).To(ContainSubstring("next\n"))
+	})
+
+	It("should succeed to load steps attached to a terminal", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    pty: true
+    steps:
+      - command: test -t 1 && echo run-tty
+      - command: test -t 1 && echo step-tty
+        pty: true
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("run-tty\r\n"))
+		Expect(out.String()).To(ContainSubstring("step-tty\r\n"))
+	})
+
+	It("should succeed to load interaction scripts", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: read -p 'Continue? [y/N] ' a; echo answer-$a
+        interact:
+          - expect: '\[y/N\]'
+            send: "y\n"
+            timeout: 5s
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("answer-y\r\n"))
+	})
+
+	It("should succeed to load speaker notes", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    notes: Run notes
+    steps:
+      - command: echo hi
+        notes:
+          - First note
+          - Second note
+`)
+		socket := filepath.Join(GinkgoT().TempDir(), "notes.sock")
+
+		server, err := demo.NewNotesServer(socket)
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err := net.Dial("unix", socket)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer conn.Close()
+
+		// The initial state shows that the connection got accepted.
+		reader := bufio.NewReader(conn)
+		_, err = reader.ReadString('\n')
+		Expect(err).ToNot(HaveOccurred())
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		runs[0].SetNotesServer(server)
+		Expect(runs[0].SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(server.Close()).To(Succeed())
+
+		received, err := io.ReadAll(reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(received)).To(ContainSubstring(`"runNotes":["Run notes"]`))
+		Expect(string(received)).To(ContainSubstring(`"notes":["First note","Second note"]`))
+	})
+
+	It("should succeed to load a prompt", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    prompt: '{{.Step}}$ '
+    steps:
+      - command: echo hi
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		out := &strings.Builder{}
+		opts.NoColor = true
+		Expect(runs[0].SetOutput(out)).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("1$ echo hi\n"))
+	})
+
+	It("should succeed to load step setup and cleanup commands", func() {
+		// Given
+		dir := GinkgoT().TempDir()
+		path := writeDefinition(`
+runs:
+  - title: Title
+    workDir: ` + dir + `
+    steps:
+      - command: test -f prepared
+        setup: touch prepared
+        cleanup:
+          - rm prepared
+          - touch cleaned
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs[0].SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+		Expect(filepath.Join(dir, "prepared")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dir, "cleaned")).To(BeAnExistingFile())
+	})
+
+	It("should succeed to load output expectations", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: printf 'hello\nworld\n'
+        expect: |
+          hello
+          world
+      - command: echo hello world
+        expectContains: lo wo
+      - command: echo version 1.2.3
+        expectMatches: 'version \d+\.\d+'
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs[0].SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(Succeed())
+	})
+
+	It("should fail to run a loaded step with unexpected output", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    steps:
+      - command: echo hello
+        expectContains: world
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs[0].SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(MatchError(ContainSubstring(
+			`output mismatch: expected output to contain "world"`,
+		)))
+	})
+
+	It("should fail with line number on invalid output expectations", func() {
+		for _, step := range []string{
+			"- text: Only text\n        expect: hello",
+			"- command: echo hello\n        expect: hello\n        expectContains: hello",
+			"- command: sleep 10\n        background: true\n        expectContains: hello",
+		} {
+			// Given
+			path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      ` + step + `
+`)
+
+			// When
+			_, err := demo.LoadRuns(path)
+
+			// Then
+			Expect(err).To(MatchError(ContainSubstring(
+				"line 4: a single expect, expectContains or expectMatches requires a command without background",
+			)), step)
+		}
+	})
+
+	It("should fail with line number on an invalid output pattern", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: echo hello
+        expectMatches: '(unclosed'
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 4: compile expectMatches: error parsing regexp")))
+	})
+
+	It("should fail with line number on cleanup without command", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - text: Only text
+        cleanup: rm -rf /tmp/demo
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 4: setup and cleanup require a command")))
+	})
+
+	It("should succeed to load requirements", func() {
+		// Given
+		path := writeDefinition(`
+runs:
+  - title: Title
+    require:
+      - binary: sh
+      - env: NOT_EXISTING_DEMO_VARIABLE
+    steps:
+      - command: echo never
+`)
+
+		// When
+		runs, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(runs[0].SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(runs[0].RunWithOptions(&opts)).To(MatchError(
+			"requirements not met: environment variable not set: NOT_EXISTING_DEMO_VARIABLE",
+		))
+	})
+
+	It("should fail with line number on requirement with multiple kinds", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    require:
+      - env: HOME
+        minVersion: "1.0"
+    steps:
+      - command: echo hi
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(
+			"line 4: a requirement needs exactly one of binary, env, file, portFree or portOpen",
+		)))
+	})
+
+	It("should fail with line number on unknown interaction field", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: cat
+        interact:
+          - expect: foo
+            sned: bar
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`line 7: unknown field "sned"`)))
+	})
+
+	It("should fail with line number on a probe without background", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: sleep 30
+        readyPort: localhost:8080
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 4: a single readyLog")))
+	})
+
+	It("should fail with line number on capture pattern without name", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: echo hi
+        capturePattern: (hi)
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 4: capturePattern requires")))
+	})
+
+	It("should fail to load a non existing file", func() {
+		// Given
+		path := filepath.Join(GinkgoT().TempDir(), "missing.yaml")
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail to load a definition without runs", func() {
+		// Given
+		path := writeDefinition("")
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("no runs defined")))
+	})
+
+	It("should fail with line number on unknown field", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: echo hi
+        canfail: true
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`line 5: unknown field "canfail"`)))
+	})
+
+	It("should fail with line number on empty step", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: echo hi
+      - canFail: true
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 5: step needs")))
+	})
+
+	It("should fail with line number on conflicting step", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - chdir: /tmp
+        command: pwd
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 4: chdir and breakPoint")))
+	})
+
+	It("should fail with line number on invalid env", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    env:
+      - VALID=1
+      - INVALID
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 5: environment variable")))
+	})
+
+	It("should fail with line number on invalid types", func() {
+		// Given
+		path := writeDefinition(`runs:
+  - title: Title
+    steps:
+      - command: echo hi
+        canFail: maybe
+`)
+
+		// When
+		_, err := demo.LoadRuns(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("line 5")))
+	})
+})
@@ -0,0 +1,158 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// markdownFence is the minimal fence used for code blocks.
+const markdownFence = "```"
+
+// ExportMarkdown writes the run as Markdown tutorial to w. The title becomes
+// a heading, descriptions and step texts become paragraphs and commands
+// become fenced code blocks. Breakpoints are omitted. Template placeholders
+// get rendered, except the ones referring to captured variables, which are
+// only available if the run has been executed.
+func (r *Run) ExportMarkdown(w io.Writer) error {
+	return r.exportMarkdown(w, nil)
+}
+
+// ExportMarkdownWithOutput executes the run headlessly via Verify and writes
+// it as Markdown tutorial to w, including the captured output of every
+// command. An error is returned if the execution failed.
+func (r *Run) ExportMarkdownWithOutput(w io.Writer, opts *Options) error {
+	result := r.Verify(opts)
+
+	return errors.Join(r.exportMarkdown(w, result), result.Err)
+}
+
+func (r *Run) exportMarkdown(w io.Writer, result *RunResult) error {
+	md := &strings.Builder{}
+	fmt.Fprintf(md, "# %s\n", r.expandedTitle())
+
+	if len(r.description) > 0 {
+		fmt.Fprintf(md, "\n%s\n", strings.Join(r.preview(r.description, 0), "\n"))
+	}
+
+	// The language of the code blocks is the name of the shell, without the
+	// path like `/bin/bash`.
+	shell := defaultShell
+	if r.options.Shell != "" {
+		shell = filepath.Base(r.options.Shell)
+	}
+
+	current := 0
+
+	for _, s := range r.steps {
+		if s.dir != "" {
+			writeCodeBlock(md, shell, "cd "+r.preview([]string{s.dir}, 0)[0])
+
+			continue
+		}
+
+		current++
+
+		if s.isBreakPoint {
+			continue
+		}
+
+		if len(s.text) > 0 {
+			fmt.Fprintf(md, "\n%s\n", strings.Join(r.preview(s.text, current), "\n"))
+		}
+
+		if len(s.command) == 0 {
+			continue
+		}
+
+		writeCodeBlock(md, shell, strings.Join(r.preview(s.command, current), " \\\n    "))
+
+		if output, ok := stepOutput(result, current); ok && output != "" {
+			md.WriteString("\nOutput:\n")
+			writeCodeBlock(md, "", strings.TrimRight(output, "\n"))
+		}
+	}
+
+	return write(w, md.String())
+}
+
+// writeCodeBlock writes a fenced code block, which uses a longer fence if
+// the content contains the default one.
+func writeCodeBlock(md *strings.Builder, language, content string) {
+	fence := markdownFence
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+
+	fmt.Fprintf(md, "\n%s%s\n%s\n%s\n", fence, language, content, fence)
+}
+
+// stepOutput returns the captured output of the step with the provided index.
+func stepOutput(result *RunResult, index int) (string, bool) {
+	if result == nil {
+		return "", false
+	}
+
+	for i := range result.Steps {
+		if result.Steps[i].Index == index && !result.Steps[i].Skipped {
+			return result.Steps[i].Output, true
+		}
+	}
+
+	return "", false
+}
+
+// exportMarkdown writes the selected runs as Markdown tutorial into the
+// file provided via the command line. Runs with failing output get exported
+// nevertheless and the demo cleanup is called after every execution, where
+// the document gets written in any case to show up to which step the
+// commands succeeded.
+func (d *Demo) exportMarkdown(ctx context.Context, cmd *cli.Command, runs []*Run) error {
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	md := &strings.Builder{}
+
+	var errs []error
+
+	for i, r := range runs {
+		if i > 0 {
+			md.WriteString("\n")
+		}
+
+		if err := d.exportRun(ctx, cmd, md, r, &opts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := os.WriteFile(cmd.String(FlagExportMarkdown), []byte(md.String()), reportFileMode); err != nil {
+		errs = append(errs, fmt.Errorf("write markdown: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// exportRun writes a single run as Markdown, which gets executed between
+// the demo setup and cleanup if the output should be included.
+func (d *Demo) exportRun(ctx context.Context, cmd *cli.Command, md io.Writer, r *Run, opts *Options) error {
+	if !cmd.Bool(FlagExportOutput) {
+		// The options of the command line apply without executing the run.
+		r.options = *opts
+
+		return r.ExportMarkdown(md)
+	}
+
+	if err := d.setup(ctx, cmd); err != nil {
+		return errors.Join(err, d.cleanup(ctx, cmd))
+	}
+
+	return errors.Join(r.ExportMarkdownWithOutput(md, opts), d.cleanup(ctx, cmd))
+}
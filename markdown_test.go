@@ -0,0 +1,246 @@
+package demo_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+	"github.com/urfave/cli/v3"
+)
+
+var _ = Describe("Markdown", func() {
+	var sut *demo.Run
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Markdown Title", "Some", "description")
+		sut.Step(demo.S("Say hello"), demo.S("echo hello"))
+		sut.Chdir("/tmp")
+		sut.BreakPoint()
+		sut.Step(nil, demo.S("echo multi", "line"))
+		sut.Step(demo.S("Just text"), nil)
+	})
+
+	It("should succeed to export a run", func() {
+		// Given
+		out := &strings.Builder{}
+
+		// When
+		err := sut.ExportMarkdown(out)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("# Markdown Title\n\n" +
+			"Some\ndescription\n\n" +
+			"Say hello\n\n" +
+			"```bash\necho hello\n```\n\n" +
+			"```bash\ncd /tmp\n```\n\n" +
+			"```bash\necho multi \\\n    line\n```\n\n" +
+			"Just text\n"))
+	})
+
+	It("should succeed to export a run with output", func() {
+		// Given
+		out := &strings.Builder{}
+
+		// When
+		err := sut.ExportMarkdownWithOutput(out, &demo.Options{})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring(
+			"```bash\necho hello\n```\n\nOutput:\n\n```\nhello\n```\n",
+		))
+		Expect(out.String()).To(ContainSubstring("```\nmulti line\n```\n"))
+	})
+
+	It("should use a longer fence if the output contains one", func() {
+		// Given
+		fenced := demo.NewRun("Fenced")
+		fenced.Step(nil, demo.S("printf '```'"))
+		out := &strings.Builder{}
+
+		// When
+		err := fenced.ExportMarkdownWithOutput(out, &demo.Options{})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("\n````bash\nprintf '```'\n````\n"))
+		Expect(out.String()).To(ContainSubstring("\n````\n```\n````\n"))
+	})
+
+	It("should fail to export a run with failing output", func() {
+		// Given
+		failing := demo.NewRun("Failing")
+		failing.Step(nil, demo.S("exit 1"))
+
+		// When
+		err := failing.ExportMarkdownWithOutput(&strings.Builder{}, &demo.Options{})
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should render the template placeholders", func() {
+		// Given
+		out := &strings.Builder{}
+		templated := demo.NewRun("Install ${{ .Data.v }}", "Version ${{ .Data.v }}")
+		dir := GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(dir, "1.2"), 0o700)).To(Succeed())
+
+		templated.SetData("v", "1.2")
+		templated.Chdir(dir + "/${{ .Data.v }}")
+		templated.Step(demo.S("Step ${{ .Step }}"), demo.S("echo ${{ .Data.v }}"), demo.CaptureAs("id"))
+		templated.Step(nil, demo.S("echo ${{ .Vars.id }} && basename $PWD"))
+
+		// When
+		err := templated.ExportMarkdownWithOutput(out, &demo.Options{})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("# Install 1.2\n\n" +
+			"Version 1.2\n\n" +
+			"```bash\ncd " + dir + "/1.2\n```\n\n" +
+			"Step 1\n\n" +
+			"```bash\necho 1.2\n```\n\n" +
+			"Output:\n\n```\n1.2\n```\n\n" +
+			"```bash\necho 1.2 && basename $PWD\n```\n\n" +
+			"Output:\n\n```\n1.2\n1.2\n```\n"))
+	})
+
+	It("should fail to export into a failing writer", func() {
+		// Given
+		// When
+		err := sut.ExportMarkdown(&errorWriter{})
+
+		// Then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should export the selected demos via the demo flags", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.md")
+
+		withArgs([]string{
+			appName, "--all", "--export-markdown", path, "--export-output",
+		}, func() {
+			run1 := demo.NewRun("Run 1")
+			run1.Step(nil, demo.S("echo first"))
+
+			run2 := demo.NewRun("Run 2")
+			run2.Step(nil, demo.S("echo second"))
+
+			sut := demo.New()
+			sut.Add(run1, "run1", "first run")
+			sut.Add(run2, "run2", "second run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("# Run 1\n"))
+		Expect(string(data)).To(ContainSubstring("\n# Run 2\n"))
+		Expect(string(data)).To(ContainSubstring("```\nsecond\n```\n"))
+	})
+
+	It("should use the shell of the demo flags", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.md")
+
+		withArgs([]string{
+			appName, "--all", "--export-markdown", path, "--shell", "zsh",
+		}, func() {
+			run := demo.NewRun("Run")
+			run.Step(nil, demo.S("echo zsh"))
+
+			sut := demo.New()
+			sut.Add(run, "run", "the run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("```zsh\necho zsh\n```\n"))
+	})
+
+	It("should use the name of an absolute shell path", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.md")
+
+		withArgs([]string{
+			appName, "--all", "--export-markdown", path, "--shell", "/bin/bash",
+		}, func() {
+			run := demo.NewRun("Run")
+			run.Step(nil, demo.S("echo bash"))
+
+			sut := demo.New()
+			sut.Add(run, "run", "the run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("```bash\necho bash\n```\n"))
+		Expect(string(data)).NotTo(ContainSubstring("/bin/bash"))
+	})
+
+	It("should write the partial document and clean up if the output fails", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.md")
+		cleanups := 0
+
+		var err error
+
+		withArgs([]string{
+			appName, "--all", "--export-markdown", path, "--export-output",
+		}, func() {
+			failing := demo.NewRun("Failing")
+			failing.Step(nil, demo.S("echo before"))
+			failing.Step(nil, demo.S("exit 1"))
+
+			passing := demo.NewRun("Passing")
+			passing.Step(nil, demo.S("echo after"))
+
+			sut := demo.New()
+			sut.Add(failing, "failing", "failing run")
+			sut.Add(passing, "passing", "passing run")
+			sut.Cleanup(func(context.Context, *cli.Command) error {
+				cleanups++
+
+				return nil
+			})
+
+			err = sut.RunE()
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("step command failed")))
+		Expect(cleanups).To(Equal(2))
+
+		data, readErr := os.ReadFile(path)
+		Expect(readErr).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("```\nbefore\n```\n"))
+		Expect(string(data)).To(ContainSubstring("```\nafter\n```\n"))
+	})
+
+	It("should render the template data of the demo flags", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "demo.md")
+
+		withArgs([]string{
+			appName, "--all", "--export-markdown", path, "--set", "v=2.0",
+		}, func() {
+			run := demo.NewRun("Run ${{ .Data.v }}")
+			run.Step(nil, demo.S("echo ${{ .Data.v }}"))
+
+			sut := demo.New()
+			sut.Add(run, "run", "the run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("# Run 2.0\n\n```bash\necho 2.0\n```\n"))
+	})
+})
@@ -0,0 +1,145 @@
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// errOutputMismatch is the error returned if the output of a step does not
+// match its expectation.
+var errOutputMismatch = errors.New("output mismatch")
+
+// Matcher verifies the captured output of a step created via Run.StepExpect.
+type Matcher interface {
+	// Match returns an error describing the difference if the output does
+	// not match the expectation.
+	Match(output string) error
+}
+
+type equalsMatcher struct{ expected string }
+
+type containsMatcher struct{ substring string }
+
+type regexpMatcher struct {
+	pattern string
+	re      *regexp.Regexp
+	err     error
+}
+
+// OutputEquals returns a Matcher which requires the combined stdout and
+// stderr of a step to be equal to the expected string. Trailing newlines are
+// ignored on both sides.
+func OutputEquals(expected string) Matcher {
+	return &equalsMatcher{expected: expected}
+}
+
+// OutputContains returns a Matcher which requires the combined stdout and
+// stderr of a step to contain the provided substring.
+func OutputContains(substring string) Matcher {
+	return &containsMatcher{substring: substring}
+}
+
+// OutputMatches returns a Matcher which requires the combined stdout and
+// stderr of a step to match the provided regular expression.
+func OutputMatches(pattern string) Matcher {
+	re, err := regexp.Compile(pattern)
+
+	return &regexpMatcher{pattern: pattern, re: re, err: err}
+}
+
+// Match implements the Matcher interface.
+func (m *equalsMatcher) Match(output string) error {
+	expected := strings.TrimRight(m.expected, "\n")
+	actual := strings.TrimRight(output, "\n")
+
+	if expected == actual {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w:\n%s", errOutputMismatch, lineDiff(expected, actual),
+	)
+}
+
+// Match implements the Matcher interface.
+func (m *containsMatcher) Match(output string) error {
+	if strings.Contains(output, m.substring) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: expected output to contain %q\n%s",
+		errOutputMismatch, m.substring, indent(output),
+	)
+}
+
+// Match implements the Matcher interface.
+func (m *regexpMatcher) Match(output string) error {
+	if m.err != nil {
+		return fmt.Errorf("compile output pattern: %w", m.err)
+	}
+
+	if m.re.MatchString(output) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: expected output to match %q\n%s",
+		errOutputMismatch, m.pattern, indent(output),
+	)
+}
+
+// lineDiff returns a line based diff between the expected and actual string.
+func lineDiff(expected, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := &strings.Builder{}
+	diff.WriteString("--- expected\n+++ actual\n")
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(diff, "  %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(diff, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(diff, "+ %s\n", b[j])
+			j++
+		}
+	}
+
+	return diff.String()
+}
+
+// indent prefixes every line of the output for readable error messages.
+func indent(output string) string {
+	trimmed := strings.TrimRight(output, "\n")
+	if trimmed == "" {
+		return "  <empty output>"
+	}
+
+	return "  " + strings.ReplaceAll(trimmed, "\n", "\n  ")
+}
@@ -0,0 +1,89 @@
+package demo_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Matcher", func() {
+	It("should succeed to match equal output", func() {
+		// Given
+		sut := demo.OutputEquals("hello\nworld")
+
+		// When
+		err := sut.Match("hello\nworld\n")
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to match different output with a diff", func() {
+		// Given
+		sut := demo.OutputEquals("first\nsecond\nthird")
+
+		// When
+		err := sut.Match("first\nchanged\nthird\n")
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("--- expected\n+++ actual\n"))
+		Expect(err.Error()).To(ContainSubstring("  first\n- second\n+ changed\n  third\n"))
+	})
+
+	It("should succeed to match a substring", func() {
+		// Given
+		sut := demo.OutputContains("world")
+
+		// When
+		err := sut.Match("hello world\n")
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to match a missing substring", func() {
+		// Given
+		sut := demo.OutputContains("missing")
+
+		// When
+		err := sut.Match("hello world\n")
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`expected output to contain "missing"`)))
+		Expect(err.Error()).To(ContainSubstring("  hello world"))
+	})
+
+	It("should succeed to match a regular expression", func() {
+		// Given
+		sut := demo.OutputMatches(`^v\d+\.\d+`)
+
+		// When
+		err := sut.Match("v1.2.3\n")
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail to match a regular expression", func() {
+		// Given
+		sut := demo.OutputMatches(`^v\d+`)
+
+		// When
+		err := sut.Match("")
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("<empty output>")))
+	})
+
+	It("should fail with an invalid regular expression", func() {
+		// Given
+		sut := demo.OutputMatches(`(`)
+
+		// When
+		err := sut.Match("anything")
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("compile output pattern")))
+	})
+})
@@ -0,0 +1,322 @@
+package demo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// menuKey is a key of the interactive menu.
+type menuKey int
+
+const (
+	menuNone menuKey = iota
+	menuUp
+	menuDown
+	menuSelect
+	menuBack
+	menuQuit
+)
+
+// menuEntry is a run which can be selected in the menu.
+type menuEntry struct {
+	run         *Run
+	description string
+}
+
+// menu lets the presenter select the run and the step to start from.
+type menu struct {
+	in      *bufio.Reader
+	inFile  *os.File
+	out     io.Writer
+	options *Options
+
+	// lines is the number of lines rendered by the last draw, which get
+	// replaced by the next one.
+	lines int
+}
+
+// useMenu returns true if the menu should be shown, which is the case if it
+// has been requested or if no run has been selected on a terminal. There is
+// nothing to select without any runs.
+func (d *Demo) useMenu(cmd *cli.Command, selected []*Run) bool {
+	if len(d.runs) == 0 && len(selected) == 0 {
+		return false
+	}
+
+	if cmd.Bool(FlagMenu) {
+		return true
+	}
+
+	if len(selected) > 0 {
+		return false
+	}
+
+	root := cmd.Root()
+	in, ok := root.Reader.(*os.File)
+
+	return ok && isTerminal(in) && isTerminal(root.Writer)
+}
+
+// menuEntries returns all registered runs followed by the selected runs
+// which have not been registered, like the ones loaded from a file.
+func (d *Demo) menuEntries(selected []*Run) []menuEntry {
+	entries := make([]menuEntry, 0, len(d.runs)+len(selected))
+	registered := map[*Run]struct{}{}
+
+	for _, x := range d.runs {
+		entries = append(entries, menuEntry{run: x.run, description: x.description})
+		registered[x.run] = struct{}{}
+	}
+
+	for _, r := range selected {
+		if _, ok := registered[r]; !ok {
+			entries = append(entries, menuEntry{run: r})
+		}
+	}
+
+	return entries
+}
+
+// runMenu shows the menu and executes the selected run from the selected
+// step, including the setup and cleanup of the demo, until the presenter
+// quits the menu.
+func (d *Demo) runMenu(ctx context.Context, cmd *cli.Command, entries []menuEntry) error {
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	root := cmd.Root()
+	m := &menu{in: bufio.NewReader(root.Reader), out: root.Writer, options: &opts}
+	m.inFile, _ = root.Reader.(*os.File)
+
+	selected := 0
+
+	for {
+		entry, skip, err := m.choose(entries, selected)
+		if err != nil {
+			return err
+		}
+
+		if entry < 0 {
+			return ErrQuit
+		}
+
+		selected = entry
+		r := entries[entry].run
+
+		runFn := func(ctx context.Context, cmd *cli.Command) error {
+			opts, err := optionsFrom(ctx, cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.SkipSteps = skip
+
+			return r.RunWithOptions(&opts)
+		}
+
+		if err := createRunSelected(d, ctx, cmd, []runAction{runFn})(); err != nil && !errors.Is(err, ErrQuit) {
+			return err
+		}
+	}
+}
+
+// choose lets the presenter select a run and the step to start from. It
+// returns the index of the run and the number of steps to skip, or -1 if the
+// presenter quit the menu.
+func (m *menu) choose(entries []menuEntry, selected int) (int, int, error) {
+	restore, raw := enterRawMode(m.inFile)
+	defer restore()
+
+	runs := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		item := e.run.expandedTitle()
+		if e.description != "" {
+			item += m.options.style(m.options.Theme.Description, " - %s", e.description)
+		}
+
+		runs = append(runs, item)
+	}
+
+	for {
+		entry, key, err := m.list(raw, "Select a demo", runs, selected)
+		if err != nil || key == menuQuit {
+			return -1, 0, errors.Join(err, m.clear(raw))
+		}
+
+		selected = entry
+		steps := entries[entry].run.menuSteps()
+
+		if len(steps) == 0 {
+			return entry, 0, m.clear(raw)
+		}
+
+		title := fmt.Sprintf("Select the step of %q to start from", entries[entry].run.expandedTitle())
+
+		step, key, err := m.list(raw, title, steps, 0)
+		if err != nil || key == menuQuit {
+			return -1, 0, errors.Join(err, m.clear(raw))
+		}
+
+		if key == menuSelect {
+			return entry, step, m.clear(raw)
+		}
+	}
+}
+
+// list draws the items and moves the cursor until an item gets selected,
+// the presenter goes back or quits. It returns the index of the item and the
+// key which ended the selection.
+func (m *menu) list(raw bool, title string, items []string, cursor int) (int, menuKey, error) {
+	cursor = max(0, min(cursor, len(items)-1))
+
+	for {
+		if err := m.draw(raw, title, items, cursor); err != nil {
+			return 0, menuNone, err
+		}
+
+		key, err := m.readKey()
+		if err != nil {
+			return 0, menuNone, err
+		}
+
+		switch key {
+		case menuUp:
+			cursor = max(0, cursor-1)
+		case menuDown:
+			cursor = min(len(items)-1, cursor+1)
+		case menuSelect, menuBack, menuQuit:
+			return cursor, key, nil
+		case menuNone:
+		}
+	}
+}
+
+// draw renders the items and replaces the previous drawing.
+func (m *menu) draw(raw bool, title string, items []string, cursor int) error {
+	if err := m.clear(raw); err != nil {
+		return err
+	}
+
+	// Line breaks in raw mode require a carriage return.
+	lines := make([]string, 0, len(items)+3)
+	lines = append(lines,
+		m.options.style(m.options.Theme.Title, "%s", title),
+		m.options.style(m.options.Theme.Description, "%s", "↑/↓ move, enter selects, ← goes back, q quits"),
+		"",
+	)
+
+	for i, item := range items {
+		prefix := "  "
+		if i == cursor {
+			prefix = m.options.style(m.options.Theme.Prompt, "%s", ">") + " "
+		}
+
+		lines = append(lines, prefix+item)
+	}
+
+	m.lines = len(lines)
+
+	return write(m.out, strings.Join(lines, "\r\n")+"\r\n")
+}
+
+// clear removes the previous drawing on terminals.
+func (m *menu) clear(raw bool) error {
+	lines := m.lines
+	m.lines = 0
+
+	if lines == 0 || !raw {
+		return nil
+	}
+
+	return write(m.out, fmt.Sprintf("\x1b[%dA\r\x1b[J", lines))
+}
+
+// readKey reads a single key, like a letter or an arrow key. The end of the
+// input quits the menu.
+func (m *menu) readKey() (menuKey, error) {
+	key, err := m.in.ReadByte()
+	if errors.Is(err, io.EOF) {
+		return menuQuit, nil
+	} else if err != nil {
+		return menuNone, fmt.Errorf("unable to read keypress: %w", err)
+	}
+
+	switch key {
+	case 'k':
+		return menuUp, nil
+	case 'j':
+		return menuDown, nil
+	case '\r', '\n', 'l':
+		return menuSelect, nil
+	case 'h', keyBackspace:
+		return menuBack, nil
+	case keyQuit, keyCtrlC:
+		return menuQuit, nil
+	case keyEscape:
+		return m.readEscapeSequence()
+	}
+
+	return menuNone, nil
+}
+
+// readEscapeSequence reads the rest of an arrow key escape sequence.
+func (m *menu) readEscapeSequence() (menuKey, error) {
+	if next, err := m.in.ReadByte(); err != nil || next != '[' {
+		return menuNone, nil //nolint:nilerr // incomplete sequences are ignored
+	}
+
+	code, err := m.in.ReadByte()
+	if err != nil {
+		return menuNone, nil //nolint:nilerr // incomplete sequences are ignored
+	}
+
+	switch code {
+	case 'A':
+		return menuUp, nil
+	case 'B':
+		return menuDown, nil
+	case 'C':
+		return menuSelect, nil
+	case 'D':
+		return menuBack, nil
+	}
+
+	return menuNone, nil
+}
+
+// menuSteps returns the menu items of all visible steps.
+func (r *Run) menuSteps() []string {
+	maximum := r.countVisibleSteps()
+	items := make([]string, 0, maximum)
+
+	for i := range r.steps {
+		s := &r.steps[i]
+		if s.dir != "" {
+			continue
+		}
+
+		number := len(items) + 1
+		label := "breakpoint"
+
+		switch {
+		case len(s.text) > 0:
+			label = r.preview(s.text, number)[0]
+		case len(s.command) > 0:
+			label = strings.Join(r.preview(s.command, number), " ")
+		}
+
+		items = append(items, fmt.Sprintf("[%d/%d] %s", number, maximum, label))
+	}
+
+	return items
+}
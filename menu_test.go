@@ -0,0 +1,149 @@
+package demo_test
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+	"github.com/urfave/cli/v3"
+)
+
+var _ = Describe("Menu", func() {
+	var (
+		sut        *demo.Demo
+		out        *strings.Builder
+		menu       *strings.Builder
+		setupCalls int
+	)
+
+	BeforeEach(func() {
+		out = &strings.Builder{}
+		menu = &strings.Builder{}
+		setupCalls = 0
+
+		first := demo.NewRun("First")
+		Expect(first.SetOutput(out)).To(Succeed())
+		first.Step(demo.S("First step"), demo.S("echo first-1"))
+
+		second := demo.NewRun("Second")
+		Expect(second.SetOutput(out)).To(Succeed())
+		second.Step(nil, demo.S("echo second-1"))
+		second.Step(demo.S("Second step"), demo.S("echo second-2"))
+
+		sut = demo.New()
+		sut.Writer = menu
+		sut.Add(first, "first", "the first demo")
+		sut.Add(second, "second", "the second demo")
+		sut.Setup(func(context.Context, *cli.Command) error {
+			setupCalls++
+
+			return nil
+		})
+	})
+
+	run := func(input string) error {
+		sut.Reader = strings.NewReader(input)
+
+		var err error
+
+		withArgs([]string{
+			appName, "--menu", "--no-color", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			err = sut.RunE()
+		})
+
+		return err
+	}
+
+	It("should list the runs and their steps", func() {
+		// Given
+		input := "j\n"
+
+		// When
+		err := run(input)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(menu.String()).To(ContainSubstring("Select a demo\r\n"))
+		Expect(menu.String()).To(ContainSubstring("> First - the first demo\r\n  Second - the second demo\r\n"))
+		Expect(menu.String()).To(ContainSubstring("  First - the first demo\r\n> Second - the second demo\r\n"))
+		Expect(menu.String()).To(ContainSubstring(`Select the step of "Second" to start from`))
+		Expect(menu.String()).To(ContainSubstring("> [1/2] echo second-1\r\n  [2/2] Second step\r\n"))
+		Expect(setupCalls).To(BeZero())
+	})
+
+	It("should start the selected run from the selected step", func() {
+		// Given
+		input := "\x1b[B\r\x1b[B\r"
+
+		// When
+		err := run(input)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).NotTo(ContainSubstring("first-1"))
+		Expect(out.String()).NotTo(ContainSubstring("second-1\n"))
+		Expect(out.String()).To(ContainSubstring("second-2\n"))
+		Expect(setupCalls).To(Equal(1))
+	})
+
+	It("should go back to the runs and show the menu again after a run", func() {
+		// Given
+		input := "j\nh" + "k\n\n" + "l\x1b[D\x1b[A\n\n"
+
+		// When
+		err := run(input)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(out.String(), "> echo first-1\n")).To(Equal(2))
+		Expect(out.String()).NotTo(ContainSubstring("second"))
+		Expect(setupCalls).To(Equal(2))
+	})
+
+	It("should quit the menu", func() {
+		// Given
+		input := "q\n"
+
+		// When
+		err := run(input)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(BeEmpty())
+		Expect(setupCalls).To(BeZero())
+	})
+
+	It("should not show the menu without any runs", func() {
+		// Given
+		empty := demo.New()
+		empty.Reader = strings.NewReader("\n")
+		empty.Writer = menu
+
+		// When
+		withArgs([]string{appName, "--menu"}, func() {
+			Expect(empty.RunE()).To(Succeed())
+		})
+
+		// Then
+		Expect(menu.String()).To(BeEmpty())
+	})
+
+	It("should not show the menu if a run has been selected", func() {
+		// Given
+		sut.Reader = strings.NewReader("q")
+
+		// When
+		withArgs([]string{
+			appName, "--second", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		// Then
+		Expect(menu.String()).To(BeEmpty())
+		Expect(out.String()).To(ContainSubstring("second-2\n"))
+	})
+})
@@ -0,0 +1,155 @@
+package demo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrQuit is the error returned by Run.RunWithOptions if the presenter quit
+// the demo. Demo treats it as regular end of all runs.
+var ErrQuit = errors.New("demo quit by presenter")
+
+// navigation is the kind of movement between steps requested by the presenter.
+type navigation int
+
+const (
+	// navNext continues with the current step, which is the default for
+	// every key without a special binding.
+	navNext navigation = iota
+
+	// navBack restarts the previous step.
+	navBack
+
+	// navRerun restarts the current step.
+	navRerun
+
+	// navQuit quits the demo.
+	navQuit
+
+	// navJump restarts at a specific step.
+	navJump
+)
+
+const (
+	keyBack      = 'b'
+	keyRerun     = 'r'
+	keyNext      = 'n'
+	keyQuit      = 'q'
+	keyJump      = 'g'
+	keyCtrlC     = 0x03
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+)
+
+// navAction is a navigation requested by the presenter. The step is the
+// visible step number for navJump.
+type navAction struct {
+	kind navigation
+	step int
+}
+
+// parseNavigation parses a line of input in non-raw mode, for example "b"
+// or "g 3". Unknown input continues with the next step.
+func parseNavigation(line string) navAction {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return navAction{kind: navNext}
+	}
+
+	switch line[0] {
+	case keyNext:
+		return navAction{kind: navNext}
+	case keyBack:
+		return navAction{kind: navBack}
+	case keyRerun:
+		return navAction{kind: navRerun}
+	case keyQuit:
+		return navAction{kind: navQuit}
+	case keyJump:
+		if n, err := strconv.Atoi(strings.TrimSpace(line[1:])); err == nil {
+			return navAction{kind: navJump, step: n}
+		}
+	}
+
+	return navAction{kind: navNext}
+}
+
+// readRawNavigation reads a single keypress in raw mode. The jump key is
+// followed by the step number and Enter, which gets echoed while typing.
+func (r *Run) readRawNavigation() (navAction, error) {
+	key, err := r.in.ReadByte()
+	if err != nil {
+		return navAction{}, fmt.Errorf("unable to read keypress: %w", err)
+	}
+
+	switch key {
+	case keyNext:
+		return navAction{kind: navNext}, nil
+	case keyBack:
+		return navAction{kind: navBack}, nil
+	case keyRerun:
+		return navAction{kind: navRerun}, nil
+	case keyQuit, keyCtrlC:
+		return navAction{kind: navQuit}, nil
+	case keyJump:
+		return r.readRawJump()
+	default:
+		return navAction{kind: navNext}, nil
+	}
+}
+
+// readRawJump reads the digits of the step to jump to until Enter is
+// pressed. Escape cancels the jump and stays at the current step.
+func (r *Run) readRawJump() (navAction, error) {
+	if err := write(r.out, string(keyJump)); err != nil {
+		return navAction{}, err
+	}
+
+	digits := ""
+
+	for {
+		key, err := r.in.ReadByte()
+		if err != nil {
+			return navAction{}, fmt.Errorf("unable to read keypress: %w", err)
+		}
+
+		switch {
+		case key == '\r' || key == '\n':
+			return parseNavigation(string(keyJump) + digits), nil
+		case key == keyEscape || key == keyCtrlC:
+			return navAction{kind: navRerun}, nil
+		case key == keyBackspace && digits != "":
+			digits = digits[:len(digits)-1]
+
+			if err := write(r.out, "\b \b"); err != nil {
+				return navAction{}, err
+			}
+		case key >= '0' && key <= '9':
+			digits += string(key)
+
+			if err := write(r.out, string(key)); err != nil {
+				return navAction{}, err
+			}
+		}
+	}
+}
+
+// target returns the visible step number to continue with after a
+// navigation other than navNext, clamped to the available steps.
+func (n navAction) target(current, maximum int) int {
+	next := current
+
+	switch n.kind {
+	case navNext:
+		next = current + 1
+	case navBack:
+		next = current - 1
+	case navRerun, navQuit:
+	case navJump:
+		next = n.step
+	}
+
+	return max(1, min(next, maximum))
+}
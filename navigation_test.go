@@ -0,0 +1,162 @@
+package demo_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Navigation", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Navigation")
+
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Immediate: true}
+	})
+
+	setInput := func(input string) {
+		Expect(sut.SetInput(strings.NewReader(input))).To(Succeed())
+	}
+
+	// count returns how often the output of the command `echo out-$((n))`
+	// has been written, which differs from the displayed command.
+	count := func(n int) int {
+		return strings.Count(out.String(), fmt.Sprintf("out-%d\n", n))
+	}
+
+	It("should rerun the current step", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		setInput("\nr\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(out.String(), "> echo out-$((1))")).To(Equal(2))
+		Expect(count(1)).To(Equal(1))
+	})
+
+	It("should go back to the previous step", func() {
+		// Given
+		sut.Step(demo.S("First"), demo.S("echo out-$((1))"))
+		sut.Step(demo.S("Second"), demo.S("echo out-$((2))"))
+		setInput("\n\nb\n\n\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(1)).To(Equal(2))
+		Expect(count(2)).To(Equal(1))
+	})
+
+	It("should stay at the first step when going back", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		setInput("b\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(1)).To(Equal(1))
+	})
+
+	It("should continue with the next key", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		setInput("n\nn\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(1)).To(Equal(1))
+	})
+
+	It("should jump to a specific step", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		sut.Step(nil, demo.S("echo out-$((2))"))
+		sut.Step(nil, demo.S("echo out-$((3))"))
+		setInput("g 3\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(1)).To(BeZero())
+		Expect(count(2)).To(BeZero())
+		Expect(count(3)).To(Equal(1))
+	})
+
+	It("should clamp jumps to the available steps", func() {
+		// Given
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		sut.Step(nil, demo.S("echo out-$((2))"))
+		setInput("g 42\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(1)).To(BeZero())
+		Expect(count(2)).To(Equal(1))
+	})
+
+	It("should quit the run and call the cleanup", func() {
+		// Given
+		cleanupCalled := false
+
+		sut.Cleanup(func() error {
+			cleanupCalled = true
+
+			return nil
+		})
+		sut.Step(nil, demo.S("echo out-$((1))"))
+		setInput("q\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(demo.ErrQuit))
+		Expect(cleanupCalled).To(BeTrue())
+		Expect(count(1)).To(BeZero())
+	})
+
+	It("should restore the working directory when going back", func() {
+		// Given
+		sut.SetWorkDir("/")
+		sut.Step(nil, demo.S("echo out-${#PWD}"))
+		sut.Chdir("/tmp")
+		sut.Step(nil, demo.S("echo out-${#PWD}"))
+		setInput("\n\nb\n\n\n\n\n")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(count(len("/"))).To(Equal(2))
+		Expect(count(len("/tmp"))).To(Equal(1))
+	})
+})
@@ -0,0 +1,306 @@
+package demo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// errNoNotesSocket is the error returned by the notes command if no socket
+// has been provided.
+var errNoNotesSocket = errors.New("notes command requires --notes-socket")
+
+const (
+	// notesClientBuffer is the number of updates buffered for every notes
+	// client before further ones get dropped.
+	notesClientBuffer = 16
+
+	// notesRefreshInterval is the interval for refreshing the elapsed time
+	// of the notes view.
+	notesRefreshInterval = time.Second
+)
+
+// WithNotes adds private speaker notes to the step, which are not part of the
+// demo output but shown by the notes command in a second terminal.
+func WithNotes(notes ...string) StepOption {
+	return func(s *step) {
+		s.notes = notes
+	}
+}
+
+// SetNotes sets private speaker notes for the whole run, which are shown by
+// the notes command together with the notes of the current step.
+func (r *Run) SetNotes(notes ...string) {
+	r.notes = notes
+}
+
+// NotesServer serves the speaker notes of the running demo on a unix socket,
+// which the notes command connects to. Every client receives the current
+// notes on connect and on every step as JSON lines.
+type NotesServer struct {
+	listener net.Listener
+	started  time.Time
+	state    *broadcaster[notesState]
+}
+
+// notesState is the state sent to the notes clients.
+type notesState struct {
+	Started  time.Time  `json:"started"`
+	Title    string     `json:"title"`
+	RunNotes []string   `json:"runNotes,omitempty"`
+	Steps    int        `json:"steps"`
+	Current  *notesStep `json:"current,omitempty"`
+	Next     *notesStep `json:"next,omitempty"`
+}
+
+// notesStep is a single step of the notes state.
+type notesStep struct {
+	Step    int      `json:"step"`
+	Text    []string `json:"text,omitempty"`
+	Notes   []string `json:"notes,omitempty"`
+	Command []string `json:"command,omitempty"`
+}
+
+// NewNotesServer starts serving the speaker notes on the unix socket at the
+// provided path.
+func NewNotesServer(path string) (*NotesServer, error) {
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+
+	started := time.Now()
+	n := &NotesServer{
+		listener: listener,
+		started:  started,
+		state:    newBroadcaster(notesState{Started: started}, notesClientBuffer),
+	}
+
+	go n.serve()
+
+	return n, nil
+}
+
+// Close stops serving the notes and disconnects all clients.
+func (n *NotesServer) Close() error {
+	err := n.listener.Close()
+	n.state.close()
+
+	if err != nil {
+		return fmt.Errorf("close notes server: %w", err)
+	}
+
+	return nil
+}
+
+// SetNotesServer publishes the speaker notes of the run via the provided
+// server.
+func (r *Run) SetNotesServer(server *NotesServer) {
+	r.notesServer = server
+}
+
+// withNotesServer serves the speaker notes of the runs while executing the
+// provided function.
+func withNotesServer(path string, runs []*Run, fn func() error) error {
+	server, err := NewNotesServer(path)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range runs {
+		r.SetNotesServer(server)
+	}
+
+	return errors.Join(fn(), server.Close())
+}
+
+func (n *NotesServer) serve() {
+	for {
+		conn, err := n.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go n.handle(conn)
+	}
+}
+
+// handle sends the current state and all updates to the client until it
+// disconnects or the server gets closed.
+func (n *NotesServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	encoder := json.NewEncoder(conn)
+
+	n.state.stream(conn, func(state notesState) error {
+		return encoder.Encode(state)
+	})
+}
+
+// publish sends the state to all clients.
+func (n *NotesServer) publish(state notesState) {
+	state.Started = n.started
+
+	n.state.update(func(s *notesState) { *s = state })
+}
+
+// setNotesStep publishes the notes of the provided visible step number and
+// the one after it.
+func (r *Run) setNotesStep(current, maximum int) {
+	if r.notesServer == nil {
+		return
+	}
+
+	state := notesState{
+		Title:    r.heading,
+		RunNotes: r.notes,
+		Steps:    maximum,
+		Current:  r.notesStep(current),
+		Next:     r.notesStep(current + 1),
+	}
+
+	r.notesServer.publish(state)
+}
+
+// notesStep returns the notes of the provided visible step number, or nil if
+// the step does not exist.
+func (r *Run) notesStep(number int) *notesStep {
+	visible := 0
+
+	for i := range r.steps {
+		s := &r.steps[i]
+		if s.dir != "" {
+			continue
+		}
+
+		visible++
+
+		if visible == number {
+			return &notesStep{
+				Step:    number,
+				Text:    r.preview(s.text, number),
+				Notes:   r.preview(s.notes, number),
+				Command: r.preview(s.command, number),
+			}
+		}
+	}
+
+	return nil
+}
+
+// preview expands the template placeholders of the lines and falls back to
+// the unexpanded lines on errors, for example if a captured variable is not
+// available yet.
+func (r *Run) preview(lines []string, number int) []string {
+	expanded, err := r.expandAll(lines, r.templateData(number))
+	if err != nil {
+		return lines
+	}
+
+	return expanded
+}
+
+// ShowNotes connects to the notes server at the provided unix socket path
+// and renders the speaker notes to the output until the demo ends or the
+// context is done.
+func ShowNotes(ctx context.Context, path string, out io.Writer) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+	if err != nil {
+		return fmt.Errorf("connect to notes server: %w", err)
+	}
+
+	defer conn.Close()
+
+	updates := make(chan notesState)
+
+	go func() {
+		defer close(updates)
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var state notesState
+			if err := json.Unmarshal(scanner.Bytes(), &state); err != nil {
+				continue
+			}
+
+			select {
+			case updates <- state:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(notesRefreshInterval)
+	defer ticker.Stop()
+
+	var state notesState
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return write(out, "Demo ended\n")
+			}
+
+			state = update
+		case <-ticker.C:
+		}
+
+		if err := write(out, renderNotes(&state, time.Now())); err != nil {
+			return err
+		}
+	}
+}
+
+// renderNotes returns the notes view for the provided state, which clears
+// the screen before.
+func renderNotes(state *notesState, now time.Time) string {
+	b := &strings.Builder{}
+	b.WriteString("\x1b[H\x1b[2J")
+
+	elapsed := now.Sub(state.Started).Truncate(time.Second)
+	fmt.Fprintf(b, "%s (%s)\n", state.Title, elapsed)
+
+	for _, note := range state.RunNotes {
+		fmt.Fprintf(b, "%s\n", note)
+	}
+
+	if state.Current == nil {
+		b.WriteString("\nWaiting for the demo to start\n")
+
+		return b.String()
+	}
+
+	fmt.Fprintf(b, "\nCurrent step [%d/%d]:\n", state.Current.Step, state.Steps)
+	renderNotesStep(b, state.Current)
+
+	if state.Next != nil {
+		fmt.Fprintf(b, "\nNext step [%d/%d]:\n", state.Next.Step, state.Steps)
+		renderNotesStep(b, state.Next)
+	}
+
+	return b.String()
+}
+
+func renderNotesStep(b *strings.Builder, s *notesStep) {
+	for _, line := range s.Text {
+		fmt.Fprintf(b, "  # %s\n", line)
+	}
+
+	for _, note := range s.Notes {
+		fmt.Fprintf(b, "  %s\n", note)
+	}
+
+	if len(s.Command) > 0 {
+		fmt.Fprintf(b, "  > %s\n", strings.Join(s.Command, " \\\n    "))
+	}
+}
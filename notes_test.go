@@ -0,0 +1,100 @@
+package demo_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Notes", func() {
+	var (
+		sut    *demo.Run
+		opts   demo.Options
+		path   string
+		server *demo.NotesServer
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Notes")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+
+		path = filepath.Join(GinkgoT().TempDir(), "notes.sock")
+
+		var err error
+		server, err = demo.NewNotesServer(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		sut.SetNotesServer(server)
+		opts = demo.Options{Auto: true, AutoTimeout: 200 * time.Millisecond, Immediate: true}
+	})
+
+	show := func() (*gbytes.Buffer, chan error, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		DeferCleanup(cancel)
+
+		out := gbytes.NewBuffer()
+		done := make(chan error, 1)
+
+		go func() {
+			done <- demo.ShowNotes(ctx, path, out)
+		}()
+
+		Eventually(out).Should(gbytes.Say("Waiting for the demo to start"))
+
+		return out, done, cancel
+	}
+
+	It("should show the notes of the current and next step", func() {
+		// Given
+		sut.SetNotes("Keep it short")
+		sut.Step(demo.S("First"), demo.S("echo one"), demo.WithNotes("Talk about one"))
+		sut.Step(demo.S("Second"), demo.S("echo ${{ .Data.word }}"), demo.WithNotes("Talk about two"))
+		sut.SetData("word", "two")
+
+		out, done, _ := show()
+
+		// When
+		Expect(sut.RunWithOptions(&opts)).To(Succeed())
+		Expect(server.Close()).To(Succeed())
+
+		// Then
+		Eventually(done).Should(Receive(BeNil()))
+
+		contents := string(out.Contents())
+		Expect(contents).To(ContainSubstring("Notes ("))
+		Expect(contents).To(ContainSubstring("Keep it short\n"))
+		Expect(contents).To(ContainSubstring("Current step [1/2]:\n  # First\n  Talk about one\n  > echo one\n"))
+		Expect(contents).To(ContainSubstring("Next step [2/2]:\n  # Second\n  Talk about two\n  > echo two\n"))
+		Expect(contents).To(ContainSubstring("Current step [2/2]:"))
+		Expect(contents).To(HaveSuffix("Demo ended\n"))
+	})
+
+	It("should stop showing the notes if the context is done", func() {
+		// Given
+		_, done, cancel := show()
+
+		// When
+		cancel()
+
+		// Then
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(server.Close()).To(Succeed())
+	})
+
+	It("should fail to show the notes without a running demo", func() {
+		// Given
+		Expect(server.Close()).To(Succeed())
+
+		// When
+		err := demo.ShowNotes(context.Background(), path, gbytes.NewBuffer())
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("connect to notes server")))
+	})
+})
@@ -0,0 +1,150 @@
+package demo
+
+import "time"
+
+// EventKind is the kind of a lifecycle event of a run.
+type EventKind int
+
+const (
+	// EventRunStarted is emitted after the setup of a run succeeded, before
+	// its title gets printed.
+	EventRunStarted EventKind = iota
+
+	// EventStepTyped is emitted after the text and command of a step have
+	// been typed, before the command gets executed.
+	EventStepTyped
+
+	// EventCommandStarted is emitted before the command of a step gets
+	// executed. It is not emitted in dry-run mode.
+	EventCommandStarted
+
+	// EventCommandFinished is emitted after the command of a step finished,
+	// including all retries. Background steps finish once their process is
+	// ready or failed to become ready.
+	EventCommandFinished
+
+	// EventBreakPoint is emitted if the run stops at a breakpoint.
+	EventBreakPoint
+
+	// EventRunFinished is emitted after the run finished, failed or has been
+	// quit by the presenter.
+	EventRunFinished
+)
+
+// String returns the name of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventRunStarted:
+		return "RunStarted"
+	case EventStepTyped:
+		return "StepTyped"
+	case EventCommandStarted:
+		return "CommandStarted"
+	case EventCommandFinished:
+		return "CommandFinished"
+	case EventBreakPoint:
+		return "BreakPoint"
+	case EventRunFinished:
+		return "RunFinished"
+	}
+
+	return "Unknown"
+}
+
+// Event is a lifecycle event of a run, which is passed to all observers.
+type Event struct {
+	// Kind is the kind of the event.
+	Kind EventKind
+
+	// Title is the title of the run.
+	Title string
+
+	// Step is the number of the current step, starting at 1. It is zero for
+	// run events.
+	Step int
+
+	// Steps is the number of visible steps of the run.
+	Steps int
+
+	// Text contains the expanded text lines of the step.
+	Text []string
+
+	// Command is the expanded command of the step as single line.
+	Command string
+
+	// ExitCode is the exit code of a finished command, or -1 if it could not
+	// be determined.
+	ExitCode int
+
+	// Duration is the execution time of a finished command, or the time
+	// waited for the readiness of a background process.
+	Duration time.Duration
+
+	// Output is the combined output of a finished command, or the output of
+	// a background process until it became ready.
+	Output string
+
+	// Err is the error of a finished command or run, if any. Quitting a run
+	// results in ErrQuit.
+	Err error
+}
+
+// Observer gets notified about the lifecycle events of runs, for example to
+// build logging, metrics, overlays or integrations. Observers are called
+// synchronously, so they should return quickly to not stall the demo.
+type Observer interface {
+	// Observe is called for every event of the run.
+	Observe(event Event)
+}
+
+// ObserverFunc is a function implementing the Observer interface.
+type ObserverFunc func(event Event)
+
+// Observe calls the function with the provided event.
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// AddObserver registers an observer for the lifecycle events of the run.
+func (r *Run) AddObserver(observer Observer) {
+	r.observers = append(r.observers, observer)
+}
+
+// AddObserver registers an observer for the lifecycle events of all runs of
+// the demo, which gets notified after the observers of the runs.
+func (d *Demo) AddObserver(observer Observer) {
+	d.observers = append(d.observers, observer)
+}
+
+// observed returns true if the run has any observers.
+func (r *Run) observed() bool {
+	return len(r.observers) > 0 || len(r.demoObservers) > 0
+}
+
+// observeCommandFinished emits the EventCommandFinished of the step.
+func (s *step) observeCommandFinished(r *Run, current int, duration time.Duration, output string, err error) {
+	r.observe(Event{
+		Kind:     EventCommandFinished,
+		Step:     current,
+		Text:     s.text,
+		Command:  s.commandLine(),
+		ExitCode: exitCode(err),
+		Duration: duration,
+		Output:   output,
+		Err:      err,
+	})
+}
+
+// observe notifies all observers of the run and the demo about the event.
+func (r *Run) observe(event Event) {
+	event.Title = r.heading
+	event.Steps = r.countVisibleSteps()
+
+	for _, observer := range r.observers {
+		observer.Observe(event)
+	}
+
+	for _, observer := range r.demoObservers {
+		observer.Observe(event)
+	}
+}
@@ -0,0 +1,197 @@
+package demo_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+	"github.com/urfave/cli/v3"
+)
+
+var _ = Describe("Observer", func() {
+	var (
+		sut    *demo.Run
+		opts   demo.Options
+		events []demo.Event
+	)
+
+	kinds := func() []demo.EventKind {
+		res := make([]demo.EventKind, 0, len(events))
+		for _, event := range events {
+			res = append(res, event.Kind)
+		}
+
+		return res
+	}
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+
+		events = nil
+		sut.AddObserver(demo.ObserverFunc(func(event demo.Event) {
+			events = append(events, event)
+		}))
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should notify about the lifecycle of a run", func() {
+		// Given
+		sut.Step(demo.S("Only text"), nil)
+		sut.StepCanFail(demo.S("Fail"), demo.S("echo failed; exit 3"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kinds()).To(Equal([]demo.EventKind{
+			demo.EventRunStarted,
+			demo.EventStepTyped,
+			demo.EventStepTyped,
+			demo.EventCommandStarted,
+			demo.EventCommandFinished,
+			demo.EventRunFinished,
+		}))
+
+		for _, event := range events {
+			Expect(event.Title).To(Equal("Title"))
+			Expect(event.Steps).To(Equal(2))
+		}
+
+		Expect(events[1].Step).To(Equal(1))
+		Expect(events[1].Text).To(Equal([]string{"Only text"}))
+		Expect(events[1].Command).To(BeEmpty())
+
+		finished := events[4]
+		Expect(finished.Step).To(Equal(2))
+		Expect(finished.Command).To(Equal("echo failed; exit 3"))
+		Expect(finished.ExitCode).To(Equal(3))
+		Expect(finished.Output).To(Equal("failed\n"))
+		Expect(finished.Duration).To(BeNumerically(">", 0))
+		Expect(finished.Err).To(HaveOccurred())
+
+		Expect(events[5].Err).ToNot(HaveOccurred())
+	})
+
+	It("should notify with the expanded title", func() {
+		// Given
+		sut = demo.NewRun("Title ${{ .Data.v }}")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+		sut.SetData("v", "1.0")
+		sut.AddObserver(demo.ObserverFunc(func(event demo.Event) {
+			events = append(events, event)
+		}))
+		sut.Step(nil, demo.S("echo hello"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(events).NotTo(BeEmpty())
+
+		for _, event := range events {
+			Expect(event.Title).To(Equal("Title 1.0"))
+		}
+	})
+
+	It("should notify about failed runs", func() {
+		// Given
+		sut.Step(nil, demo.S("exit 1"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(events[len(events)-1].Kind).To(Equal(demo.EventRunFinished))
+		Expect(events[len(events)-1].Err).To(MatchError(err))
+	})
+
+	It("should notify about the readiness of background commands", func() {
+		// Given
+		sut.StepBackground(nil, demo.S("echo server ready; sleep 10"), demo.WaitForLog("server ready"))
+		sut.StepBackground(nil, demo.S("exit 2"), demo.WaitForLog("never"), demo.WithTimeout(time.Second))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("step background command failed")))
+		Expect(kinds()).To(Equal([]demo.EventKind{
+			demo.EventRunStarted,
+			demo.EventStepTyped,
+			demo.EventCommandStarted,
+			demo.EventCommandFinished,
+			demo.EventStepTyped,
+			demo.EventCommandStarted,
+			demo.EventCommandFinished,
+			demo.EventRunFinished,
+		}))
+
+		Expect(events[3].Step).To(Equal(1))
+		Expect(events[3].Err).ToNot(HaveOccurred())
+		Expect(events[3].Output).To(Equal("server ready\n"))
+		Expect(events[3].Duration).To(BeNumerically(">", 0))
+		Expect(events[6].Step).To(Equal(2))
+		Expect(events[6].Err).To(HaveOccurred())
+	})
+
+	It("should notify about breakpoints", func() {
+		// Given
+		Expect(sut.SetInput(strings.NewReader("\n"))).To(Succeed())
+		opts.BreakPoint = true
+		sut.BreakPoint()
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kinds()).To(ContainElement(demo.EventBreakPoint))
+	})
+
+	It("should not notify about commands in dry-run mode", func() {
+		// Given
+		opts.DryRun = true
+		sut.Step(nil, demo.S("echo hello"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(kinds()).To(Equal([]demo.EventKind{
+			demo.EventRunStarted, demo.EventStepTyped, demo.EventRunFinished,
+		}))
+	})
+
+	It("should notify the observers of the demo", func() {
+		var titles []string
+
+		withArgs([]string{
+			appName, "--all", autoFlag, autoTimeoutFlag, immediateFlag,
+		}, func() {
+			sut.Step(nil, demo.S("echo hello"))
+
+			d := demo.New()
+			d.Add(sut, "run", "observed run")
+			d.AddObserver(demo.ObserverFunc(func(event demo.Event) {
+				if event.Kind == demo.EventRunFinished {
+					titles = append(titles, event.Title)
+				}
+			}))
+			d.Setup(func(context.Context, *cli.Command) error { return nil })
+
+			Expect(d.RunE()).To(Succeed())
+		})
+
+		Expect(titles).To(Equal([]string{"Title"}))
+		Expect(kinds()).To(ContainElement(demo.EventCommandFinished))
+	})
+})
@@ -0,0 +1,418 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// requirementTimeout is the maximum duration of a single requirement check,
+// like executing the version command of a binary.
+const requirementTimeout = 10 * time.Second
+
+var (
+	// errRequirementsNotMet is the error returned if a run gets executed
+	// without fulfilling its requirements.
+	errRequirementsNotMet = errors.New("requirements not met")
+
+	// errPreflightFailed is the error returned if at least one requirement is
+	// not met in preflight mode.
+	errPreflightFailed = errors.New("preflight failed")
+
+	// errVersionNotFound is the error returned if the version of a binary
+	// cannot be found in the output of its version command.
+	errVersionNotFound = errors.New("version not found")
+
+	// errVersionTooOld is the error returned if the version of a binary is
+	// lower than the required one.
+	errVersionTooOld = errors.New("version too old")
+
+	// errEnvNotSet is the error returned for required environment variables
+	// which are not set.
+	errEnvNotSet = errors.New("environment variable not set")
+
+	// errFileNotFound is the error returned for required files which do not
+	// exist.
+	errFileNotFound = errors.New("file not found")
+
+	// errPortInUse is the error returned for required ports which are already
+	// in use.
+	errPortInUse = errors.New("port in use")
+
+	// errPortNotOpen is the error returned for required ports nothing listens
+	// on.
+	errPortNotOpen = errors.New("port not open")
+)
+
+// defaultVersionPattern matches the first dotted version number, like
+// `1.28.3` in `Client Version: v1.28.3`.
+var defaultVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// versionNumber matches the numeric parts of a version, like `1.28`.
+var versionNumber = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// Requirement is a precondition of a run, like a binary which needs to be
+// installed. Requirements get checked before the setup of a run and by the
+// preflight mode of the demo.
+type Requirement interface {
+	// Check returns nil if the requirement is met. The environment contains
+	// the variables of the process and the ones set via Run.SetEnv.
+	Check(ctx context.Context, env map[string]string) error
+
+	// String returns a short description of the requirement.
+	String() string
+}
+
+// BinaryRequirement is a Requirement for an executable in PATH, optionally
+// with a minimal version.
+type BinaryRequirement struct {
+	// Name is the name of the executable or a path to it.
+	Name string
+
+	// MinVersion is the minimal version, like `1.28`. Any version is accepted
+	// if empty.
+	MinVersion string
+
+	// VersionArgs are the arguments the binary gets executed with to print
+	// its version. Defaults to `--version`.
+	VersionArgs []string
+
+	// VersionPattern is the regular expression matching the version in the
+	// combined output of the version command. The first submatch gets used
+	// if the pattern contains groups. Defaults to the first dotted version
+	// number.
+	VersionPattern string
+}
+
+// RequireBinary returns a Requirement for an executable in PATH with a
+// minimal version, which gets parsed from the output of `name --version`.
+// Every version is accepted if minVersion is empty. Use a BinaryRequirement
+// directly for customizing the version command and pattern.
+func RequireBinary(name, minVersion string) Requirement {
+	return &BinaryRequirement{Name: name, MinVersion: minVersion}
+}
+
+// Check looks up the binary and compares its version with the minimal one.
+func (b *BinaryRequirement) Check(ctx context.Context, env map[string]string) error {
+	path, err := lookPath(b.Name, env["PATH"])
+	if err != nil {
+		return err
+	}
+
+	if b.MinVersion == "" {
+		return nil
+	}
+
+	pattern := defaultVersionPattern
+	if b.VersionPattern != "" {
+		if pattern, err = regexp.Compile(b.VersionPattern); err != nil {
+			return fmt.Errorf("compile version pattern: %w", err)
+		}
+	}
+
+	args := b.VersionArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requirementTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = envList(env)
+
+	// Some tools print their version to stderr or exit non-zero for it, so
+	// only the output decides.
+	out, _ := cmd.CombinedOutput()
+
+	match := pattern.FindSubmatch(out)
+	if match == nil {
+		return fmt.Errorf("%w: %s %s", errVersionNotFound, b.Name, strings.Join(args, " "))
+	}
+
+	version := string(match[0])
+	if len(match) > 1 {
+		version = string(match[1])
+	}
+
+	if compareVersions(version, b.MinVersion) < 0 {
+		return fmt.Errorf("%w: %s %s is lower than %s", errVersionTooOld, b.Name, version, b.MinVersion)
+	}
+
+	return nil
+}
+
+func (b *BinaryRequirement) String() string {
+	if b.MinVersion == "" {
+		return "binary " + b.Name
+	}
+
+	return fmt.Sprintf("binary %s >= %s", b.Name, b.MinVersion)
+}
+
+// lookPath returns the path of the executable within the provided PATH.
+func lookPath(name, path string) (string, error) {
+	if strings.ContainsRune(name, '/') {
+		if isExecutable(name) {
+			return name, nil
+		}
+	} else {
+		for _, p := range filepath.SplitList(path) {
+			if candidate := filepath.Join(p, name); p != "" && isExecutable(candidate) {
+				return candidate, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", errExecutableNotFound, name)
+}
+
+// compareVersions compares the numeric parts of two versions, where missing
+// parts count as zero. Any prefix like `v` is ignored.
+func compareVersions(a, b string) int {
+	x, y := versionParts(a), versionParts(b)
+
+	for len(x) < len(y) {
+		x = append(x, 0)
+	}
+
+	for len(y) < len(x) {
+		y = append(y, 0)
+	}
+
+	return slices.Compare(x, y)
+}
+
+func versionParts(version string) []int {
+	var res []int
+
+	for _, part := range strings.Split(versionNumber.FindString(version), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+
+		res = append(res, n)
+	}
+
+	return res
+}
+
+// requirementFunc is a Requirement based on a check function.
+type requirementFunc struct {
+	description string
+	check       func(ctx context.Context, env map[string]string) error
+}
+
+func (r *requirementFunc) Check(ctx context.Context, env map[string]string) error {
+	return r.check(ctx, env)
+}
+
+func (r *requirementFunc) String() string {
+	return r.description
+}
+
+// RequireEnv returns a Requirement for a non-empty environment variable.
+func RequireEnv(name string) Requirement {
+	return &requirementFunc{
+		description: "env " + name,
+		check: func(_ context.Context, env map[string]string) error {
+			if env[name] == "" {
+				return fmt.Errorf("%w: %s", errEnvNotSet, name)
+			}
+
+			return nil
+		},
+	}
+}
+
+// RequireFile returns a Requirement for an existing file or directory.
+func RequireFile(path string) Requirement {
+	return &requirementFunc{
+		description: "file " + path,
+		check: func(context.Context, map[string]string) error {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("%w: %s", errFileNotFound, path)
+			}
+
+			return nil
+		},
+	}
+}
+
+// RequirePortFree returns a Requirement for a TCP address which is not in
+// use, like `:8080` for a server started by the demo.
+func RequirePortFree(address string) Requirement {
+	return &requirementFunc{
+		description: "free port " + address,
+		check: func(ctx context.Context, _ map[string]string) error {
+			l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errPortInUse, address)
+			}
+
+			if err := l.Close(); err != nil {
+				return fmt.Errorf("close listener: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+// RequirePortOpen returns a Requirement for a TCP address which accepts
+// connections, like `localhost:5000` for a registry used by the demo.
+func RequirePortOpen(address string) Requirement {
+	return &requirementFunc{
+		description: "open port " + address,
+		check: func(ctx context.Context, _ map[string]string) error {
+			ctx, cancel := context.WithTimeout(ctx, requirementTimeout)
+			defer cancel()
+
+			conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errPortNotOpen, address)
+			}
+
+			if err := conn.Close(); err != nil {
+				return fmt.Errorf("close connection: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Require adds requirements to the run, which get checked before its setup.
+// The run fails without executing any step if a requirement is not met.
+// Binaries are only checked for runs executed by the LocalExecutor.
+func (r *Run) Require(requirements ...Requirement) {
+	r.requirements = append(r.requirements, requirements...)
+}
+
+// requirementCheck is the outcome of checking a single requirement.
+type requirementCheck struct {
+	requirement Requirement
+	err         error
+
+	// skipped is true if the requirement has not been checked.
+	skipped bool
+}
+
+// checkRequirements checks all requirements of the run. Binaries are not
+// checked if the run uses an executor other than the LocalExecutor, because
+// they refer to another machine then.
+func (r *Run) checkRequirements(ctx context.Context) []requirementCheck {
+	env := environment(r.env)
+	res := make([]requirementCheck, 0, len(r.requirements))
+
+	for _, req := range r.requirements {
+		if _, ok := req.(*BinaryRequirement); ok && !r.localExecution() {
+			res = append(res, requirementCheck{requirement: req, skipped: true})
+
+			continue
+		}
+
+		res = append(res, requirementCheck{requirement: req, err: req.Check(ctx, env)})
+	}
+
+	return res
+}
+
+// requirementsError returns an error containing all unmet requirements of
+// the run, or nil if all are met.
+func (r *Run) requirementsError(ctx context.Context) error {
+	var errs []error
+
+	for _, c := range r.checkRequirements(ctx) {
+		if c.err != nil {
+			errs = append(errs, c.err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", errRequirementsNotMet, errors.Join(errs...))
+}
+
+// preflight checks the requirements of all runs without executing them and
+// prints a table of every requirement and its status.
+func (d *Demo) preflight(ctx context.Context, cmd *cli.Command) error {
+	runs, err := d.allRuns(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+
+	if err := write(w, "RUN\tREQUIREMENT\tSTATUS\n"); err != nil {
+		return err
+	}
+
+	total, missing := 0, 0
+
+	for _, r := range runs {
+		// The template data of the flags is part of the options.
+		r.options = opts
+		title := r.expandedTitle()
+
+		for _, c := range r.checkRequirements(ctx) {
+			total++
+
+			status := "ok"
+
+			switch {
+			case c.skipped:
+				status = "skipped: not checked for other executors"
+			case c.err != nil:
+				missing++
+				status = "missing: " + c.err.Error()
+			}
+
+			if err := write(w, fmt.Sprintf("%s\t%s\t%s\n", title, c.requirement, status)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write preflight table: %w", err)
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d of %d requirements not met", errPreflightFailed, missing, total)
+	}
+
+	return nil
+}
+
+// envList converts the environment into the `KEY=VALUE` form of processes.
+func envList(env map[string]string) []string {
+	res := make([]string, 0, len(env))
+	for key, value := range env {
+		res = append(res, key+"="+value)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
@@ -0,0 +1,283 @@
+package demo_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Preflight", func() {
+	var (
+		ctx context.Context
+		dir string
+		env map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		env = map[string]string{"PATH": dir}
+
+		script := "#!/bin/sh\nif [ \"$1\" = --version ]; then echo 'tool version v1.12.3'; else echo 'release: 2.1'; fi\n"
+		Expect(os.WriteFile(filepath.Join(dir, "tool"), []byte(script), 0o700)).To(Succeed())
+	})
+
+	It("should succeed if the binary has the minimal version", func() {
+		// Given
+		requirements := []demo.Requirement{
+			demo.RequireBinary("tool", ""),
+			demo.RequireBinary("tool", "1.9"),
+			demo.RequireBinary("tool", "1.12.3"),
+			demo.RequireBinary(filepath.Join(dir, "tool"), "v1"),
+		}
+
+		for _, req := range requirements {
+			// When
+			err := req.Check(ctx, env)
+
+			// Then
+			Expect(err).ToNot(HaveOccurred(), req.String())
+		}
+	})
+
+	It("should fail if the binary is too old", func() {
+		// Given
+		req := demo.RequireBinary("tool", "1.13")
+
+		// When
+		err := req.Check(ctx, env)
+
+		// Then
+		Expect(err).To(MatchError("version too old: tool 1.12.3 is lower than 1.13"))
+		Expect(req.String()).To(Equal("binary tool >= 1.13"))
+	})
+
+	It("should fail if the binary does not exist", func() {
+		// Given
+		req := demo.RequireBinary("not-existing-tool", "")
+
+		// When
+		err := req.Check(ctx, env)
+
+		// Then
+		Expect(err).To(MatchError("executable not found: not-existing-tool"))
+	})
+
+	It("should use the version arguments and pattern", func() {
+		// Given
+		req := &demo.BinaryRequirement{
+			Name:           "tool",
+			MinVersion:     "2",
+			VersionArgs:    []string{"release"},
+			VersionPattern: `release: (\S+)`,
+		}
+
+		// When
+		err := req.Check(ctx, env)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail if the version cannot be found", func() {
+		// Given
+		req := &demo.BinaryRequirement{Name: "tool", MinVersion: "1", VersionPattern: `build (\d+)`}
+
+		// When
+		err := req.Check(ctx, env)
+
+		// Then
+		Expect(err).To(MatchError("version not found: tool --version"))
+	})
+
+	It("should check environment variables and files", func() {
+		// Given
+		env["SET"] = "value"
+
+		// When
+		errs := []error{
+			demo.RequireEnv("SET").Check(ctx, env),
+			demo.RequireEnv("UNSET").Check(ctx, env),
+			demo.RequireFile(filepath.Join(dir, "tool")).Check(ctx, env),
+			demo.RequireFile("/not/existing").Check(ctx, env),
+		}
+
+		// Then
+		Expect(errs[0]).ToNot(HaveOccurred())
+		Expect(errs[1]).To(MatchError("environment variable not set: UNSET"))
+		Expect(errs[2]).ToNot(HaveOccurred())
+		Expect(errs[3]).To(MatchError("file not found: /not/existing"))
+	})
+
+	It("should check ports", func() {
+		// Given
+		l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+
+		address := l.Addr().String()
+
+		// When
+		inUse := demo.RequirePortFree(address).Check(ctx, env)
+		open := demo.RequirePortOpen(address).Check(ctx, env)
+
+		Expect(l.Close()).To(Succeed())
+
+		free := demo.RequirePortFree(address).Check(ctx, env)
+		closed := demo.RequirePortOpen(address).Check(ctx, env)
+
+		// Then
+		Expect(inUse).To(MatchError("port in use: " + address))
+		Expect(open).ToNot(HaveOccurred())
+		Expect(free).ToNot(HaveOccurred())
+		Expect(closed).To(MatchError("port not open: " + address))
+	})
+
+	It("should check the requirements before the setup of a run", func() {
+		// Given
+		out := &strings.Builder{}
+		setupCalled := false
+
+		sut := demo.NewRun("Title")
+		Expect(sut.SetOutput(out)).To(Succeed())
+		sut.SetEnv("PATH=" + dir)
+		sut.Setup(func() error {
+			setupCalled = true
+
+			return nil
+		})
+		sut.Require(demo.RequireBinary("tool", "2"), demo.RequireEnv("UNSET_DEMO_VARIABLE"))
+		sut.Step(nil, demo.S("echo never"))
+
+		// When
+		err := sut.RunWithOptions(&demo.Options{Auto: true, Immediate: true})
+
+		// Then
+		Expect(err).To(MatchError(
+			"requirements not met: version too old: tool 1.12.3 is lower than 2\n" +
+				"environment variable not set: UNSET_DEMO_VARIABLE",
+		))
+		Expect(setupCalled).To(BeFalse())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("should not check binaries of other executors", func() {
+		// Given
+		setupCalled := false
+
+		sut := demo.NewRun("Container")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+		Expect(sut.SetExecutor(&demo.ContainerExecutor{Container: "demo"})).To(Succeed())
+		sut.Setup(func() error {
+			setupCalled = true
+
+			return nil
+		})
+		sut.Require(demo.RequireBinary("only-in-container", "1.0"))
+
+		// When
+		err := sut.RunWithOptions(&demo.Options{Auto: true, Immediate: true})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setupCalled).To(BeTrue())
+	})
+
+	It("should print skipped binaries of other executors", func() {
+		// Given
+		out := &strings.Builder{}
+
+		sut := demo.NewRun("Container")
+		Expect(sut.SetExecutor(&demo.ContainerExecutor{Container: "demo"})).To(Succeed())
+		sut.Require(demo.RequireBinary("only-in-container", "1.0"))
+
+		var err error
+
+		// When
+		withArgs([]string{appName, "--preflight"}, func() {
+			d := demo.New()
+			d.Writer = out
+			d.Add(sut, "container", "run in a container")
+			err = d.RunE()
+		})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("binary only-in-container >= 1.0  skipped: not checked for other executors"))
+	})
+
+	It("should not check the requirements in dry-run mode", func() {
+		// Given
+		sut := demo.NewRun("Title")
+		Expect(sut.SetOutput(&strings.Builder{})).To(Succeed())
+		sut.Require(demo.RequireFile("/not/existing"))
+		sut.Step(nil, demo.S("echo dry"))
+
+		// When
+		err := sut.RunWithOptions(&demo.Options{Auto: true, Immediate: true, DryRun: true})
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should print the expanded titles", func() {
+		// Given
+		out := &strings.Builder{}
+
+		r := demo.NewRun("Run ${{ .Data.v }}")
+		r.Require(demo.RequireFile(dir))
+
+		// When
+		withArgs([]string{appName, "--preflight", "--set", "v=2.0"}, func() {
+			d := demo.New()
+			d.Writer = out
+			d.Add(r, "run", "templated run")
+			Expect(d.RunE()).To(Succeed())
+		})
+
+		// Then
+		Expect(out.String()).To(ContainSubstring("Run 2.0  file " + dir))
+	})
+
+	It("should print a table of the requirements and fail if some are not met", func() {
+		// Given
+		out := &strings.Builder{}
+
+		first := demo.NewRun("First")
+		first.SetEnv("PATH=" + dir)
+		first.Require(demo.RequireBinary("tool", "1.0"), demo.RequireFile("/not/existing"))
+		first.Step(nil, demo.S("touch "+filepath.Join(dir, "executed")))
+
+		second := demo.NewRun("Second")
+		second.Require(demo.RequireEnv("UNSET_DEMO_VARIABLE"))
+
+		third := demo.NewRun("Third")
+
+		var err error
+
+		// When
+		withArgs([]string{appName, "--preflight"}, func() {
+			d := demo.New()
+			d.Writer = out
+			d.Add(first, "first", "first run")
+			d.Add(second, "second", "second run")
+			d.Add(third, "third", "run without requirements")
+			err = d.RunE()
+		})
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("preflight failed: 2 of 3 requirements not met")))
+		Expect(out.String()).To(Equal(
+			"RUN     REQUIREMENT              STATUS\n" +
+				"First   binary tool >= 1.0       ok\n" +
+				"First   file /not/existing       missing: file not found: /not/existing\n" +
+				"Second  env UNSET_DEMO_VARIABLE  missing: environment variable not set: UNSET_DEMO_VARIABLE\n",
+		))
+		Expect(filepath.Join(dir, "executed")).NotTo(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,116 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPrompt is the prompt displayed in front of commands if the run has
+// no prompt template.
+const DefaultPrompt = "> "
+
+// SetPrompt sets the template of the prompt displayed in front of commands,
+// like `{{.User}}@{{.Host}}:{{.Dir}}$ `. Prompt templates use the default Go
+// template delimiters and provide the following fields:
+//
+//	.Dir       the working directory, abbreviated by ~ for the home directory
+//	.Base      the last element of the working directory
+//	.User      the name of the current user
+//	.Host      the host name
+//	.Branch    the git branch of the working directory, if any
+//	.Time      the current time, like {{.Time.Format "15:04"}}
+//	.ExitCode  the exit code of the previous command
+//	.Step      the current step number
+func (r *Run) SetPrompt(prompt string) {
+	r.prompt = prompt
+}
+
+// promptData are the values available in prompt templates.
+type promptData struct {
+	Dir      string
+	Base     string
+	User     string
+	Host     string
+	Time     time.Time
+	ExitCode int
+	Step     int
+
+	ctx context.Context //nolint:containedctx // only used for determining the branch
+	dir string
+}
+
+// Branch returns the git branch of the working directory, or an empty
+// string if it is not a git repository. It only gets determined if the
+// prompt template refers to it.
+func (p promptData) Branch() string {
+	//nolint:gosec // the working directory is provided by the demo
+	cmd := exec.CommandContext(p.ctx, "git", "-C", p.dir, "symbolic-ref", "--short", "HEAD")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// renderPrompt returns the colored prompt for the provided step number.
+func (r *Run) renderPrompt(step int) (string, error) {
+	if r.prompt == "" {
+		return r.style(r.theme().Prompt, "%s", strings.TrimSpace(DefaultPrompt)) + " ", nil
+	}
+
+	tmpl, err := template.New("prompt").Parse(r.prompt)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt: %w", err)
+	}
+
+	res := &strings.Builder{}
+	if err := tmpl.Execute(res, r.promptData(step)); err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+
+	// Trailing whitespace stays uncolored.
+	prompt := strings.TrimRight(res.String(), " ")
+
+	return r.style(r.theme().Prompt, "%s", prompt) + res.String()[len(prompt):], nil
+}
+
+func (r *Run) promptData(step int) promptData {
+	dir := r.dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+
+	data := promptData{
+		Dir:      dir,
+		Base:     filepath.Base(dir),
+		Time:     time.Now(),
+		ExitCode: r.exitCode,
+		Step:     step,
+		ctx:      r.options.Context,
+		dir:      dir,
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		rel, err := filepath.Rel(home, dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			data.Dir = filepath.Join("~", rel)
+		}
+	}
+
+	if u, err := user.Current(); err == nil {
+		data.User = u.Username
+	}
+
+	data.Host, _ = os.Hostname()
+
+	return data
+}
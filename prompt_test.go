@@ -0,0 +1,134 @@
+package demo_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Prompt", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+		dir  string
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		dir = filepath.Join(GinkgoT().TempDir(), "project")
+		Expect(os.Mkdir(dir, 0o700)).To(Succeed())
+		sut.SetWorkDir(dir)
+
+		opts = demo.Options{Auto: true, Immediate: true, NoColor: true}
+	})
+
+	It("should use the default prompt", func() {
+		// Given
+		sut.Step(nil, demo.S("echo hi"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("\n> echo hi\n"))
+	})
+
+	It("should render the working directory and the last exit code", func() {
+		// Given
+		sut.SetPrompt("[{{.Base}} {{.ExitCode}}]$ ")
+		sut.StepCanFail(nil, demo.S("exit 3"))
+		sut.Step(nil, demo.S("echo hi"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("[project 0]$ exit 3\n"))
+		Expect(out.String()).To(ContainSubstring("[project 3]$ echo hi\n"))
+	})
+
+	It("should follow directory changes", func() {
+		// Given
+		other := GinkgoT().TempDir()
+		sut.SetPrompt("{{.Base}}$ ")
+		sut.Chdir(other)
+		sut.Step(nil, demo.S("pwd"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("project$ cd " + other + "\n"))
+		Expect(out.String()).To(ContainSubstring(filepath.Base(other) + "$ pwd\n"))
+	})
+
+	It("should abbreviate the home directory", func() {
+		// Given
+		home, err := os.UserHomeDir()
+		Expect(err).ToNot(HaveOccurred())
+
+		sut.SetWorkDir(home)
+		sut.SetPrompt("{{.Dir}}$ ")
+		sut.Step(nil, demo.S("true"))
+
+		// When
+		err = sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("~$ true\n"))
+	})
+
+	It("should render the git branch", func() {
+		// Given
+		Expect(exec.Command("git", "init", "-q", "-b", "demo-branch", dir).Run()).To(Succeed())
+		sut.SetPrompt("({{.Branch}})$ ")
+		sut.Step(nil, demo.S("true"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("(demo-branch)$ true\n"))
+	})
+
+	It("should render the time and step", func() {
+		// Given
+		sut.SetPrompt(`{{.Time.Format "2006"}} {{.Step}}$ `)
+		sut.Step(nil, demo.S("true"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring(strconv.Itoa(time.Now().Year()) + " 1$ true\n"))
+	})
+
+	It("should fail on an invalid prompt template", func() {
+		// Given
+		sut.SetPrompt("{{.Missing}}")
+		sut.Step(nil, demo.S("true"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("render prompt")))
+	})
+})
@@ -0,0 +1,53 @@
+package demo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+)
+
+// errPTYSession is the error returned for PTY steps in session mode.
+var errPTYSession = errors.New("pty steps are not supported in session mode")
+
+// WithPTY executes the step command attached to a pseudo-terminal, so that
+// colorized, interactive and full-screen programs behave like they would when
+// typed by hand. The terminal size mirrors the one of the presenter and
+// keystrokes are forwarded to the command if the input is a terminal.
+//
+// The standard output and error of the command cannot be distinguished and
+// line endings are converted to "\n" for matchers, captures and reports.
+func WithPTY() StepOption {
+	return func(s *step) {
+		s.pty = true
+	}
+}
+
+// SetPTY enables or disables the execution of all step commands of the run
+// attached to a pseudo-terminal, like WithPTY does for a single step.
+func (r *Run) SetPTY(enabled bool) {
+	r.usePTY = enabled
+}
+
+// usesPTY returns true if the command of the step gets attached to a
+// pseudo-terminal.
+func (r *Run) usesPTY(s *step) bool {
+	return s.pty || r.usePTY || len(s.script) > 0
+}
+
+// presenterTerminal returns the terminal of the presenter, which is used to
+// determine the size of pseudo-terminals.
+func (r *Run) presenterTerminal() *os.File {
+	if r.inFile != nil && isTerminal(r.inFile) {
+		return r.inFile
+	}
+
+	return os.Stdout
+}
+
+// normalizeNewlines converts the terminal line endings ("\r\n") of the
+// buffer into "\n".
+func normalizeNewlines(b *bytes.Buffer) {
+	data := bytes.ReplaceAll(b.Bytes(), []byte("\r\n"), []byte("\n"))
+	b.Reset()
+	b.Write(data)
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package demo
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// errPTYUnsupported is the error returned for PTY steps on platforms without
+// pseudo-terminals.
+var errPTYUnsupported = errors.New("pty steps are not supported on this platform")
+
+// execPTY is not supported on this platform.
+func (r *Run) execPTY(context.Context, string, []Interaction, io.Writer) error {
+	return errPTYUnsupported
+}
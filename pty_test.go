@@ -0,0 +1,99 @@
+package demo_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("PTY", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should run a step attached to a terminal", func() {
+		// Given
+		sut.Step(nil, demo.S("test -t 0 && test -t 1 && echo is-a-tty"), demo.WithPTY())
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("is-a-tty\r\n"))
+	})
+
+	It("should not attach regular steps to a terminal", func() {
+		// Given
+		sut.Step(nil, demo.S("test -t 1 || echo no-tty"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("no-tty\n"))
+	})
+
+	It("should provide a terminal size", func() {
+		// Given
+		sut.StepExpect(nil, demo.S("stty size"), demo.OutputMatches(`^\d+ \d+\n$`), demo.WithPTY())
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should normalize line endings for matchers and captures", func() {
+		// Given
+		sut.SetPTY(true)
+		sut.StepExpect(nil, demo.S("printf 'first\\nsecond\\n'"), demo.OutputEquals("first\nsecond"))
+		sut.Step(nil, demo.S("echo captured"), demo.CaptureAs("value"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+
+		value, _ := sut.Var("value")
+		Expect(value).To(Equal("captured"))
+	})
+
+	It("should fail with the exit status of the command", func() {
+		// Given
+		sut.Step(nil, demo.S("exit 3"), demo.WithPTY())
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+	})
+
+	It("should fail in session mode", func() {
+		// Given
+		sut.SetSession(true)
+		sut.Step(nil, demo.S("echo hi"), demo.WithPTY())
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("pty steps are not supported in session mode")))
+	})
+})
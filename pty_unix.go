@@ -0,0 +1,174 @@
+//go:build unix
+
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+)
+
+// ptyInputBufferSize is the size of the buffer for forwarding input.
+const ptyInputBufferSize = 1024
+
+// execPTY executes the command attached to a new pseudo-terminal and writes
+// its output to out. The command is driven by the script if provided, or by
+// the presenter input otherwise.
+func (r *Run) execPTY(ctx context.Context, command string, script []Interaction, out io.Writer) error {
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		return fmt.Errorf("open pty: %w", err)
+	}
+
+	resize := func() {
+		width, height := terminalSize(r.presenterTerminal())
+		//nolint:gosec // terminal sizes fit into uint16
+		_ = pty.Setsize(ptmx, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+	}
+	resize()
+
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+
+	defer signal.Stop(resized)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	output := &syncBuffer{}
+	copied := make(chan struct{})
+
+	go func() {
+		defer close(copied)
+
+		_, _ = io.Copy(io.MultiWriter(out, output), ptmx)
+	}()
+
+	restore, raw := func() {}, false
+	if len(script) == 0 {
+		restore, raw = r.enterRawMode()
+	}
+
+	stop := make(chan struct{})
+	interacted := make(chan error, 1)
+
+	go func() {
+		if len(script) > 0 {
+			interacted <- r.interact(ctx, script, ptmx, output, stop)
+
+			return
+		}
+
+		if raw {
+			forwardInput(r.inFile, ptmx, stop)
+		}
+
+		interacted <- nil
+	}()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- r.executorOrDefault().Execute(ctx, &Execution{
+			Command: command,
+			Dir:     r.dir,
+			Env:     r.env,
+			Stdin:   tty,
+			Stdout:  tty,
+			Stderr:  tty,
+			TTY:     true,
+		})
+	}()
+
+	var execErr, interactErr error
+
+wait:
+	for {
+		select {
+		case <-resized:
+			resize()
+		case interactErr = <-interacted:
+			interacted = nil
+
+			// A failed script would otherwise wait for the command forever.
+			if interactErr != nil {
+				cancel()
+			}
+		case execErr = <-done:
+			break wait
+		}
+	}
+
+	// Reading the remaining output ends once all references to the terminal
+	// are closed, which child processes of the command may still hold.
+	closeErr := tty.Close()
+
+	select {
+	case <-copied:
+	case <-time.After(outputWaitDelay):
+	}
+
+	// The script sees the whole output before it gets stopped.
+	close(stop)
+
+	if interacted != nil {
+		interactErr = <-interacted
+	}
+
+	restore()
+
+	closeErr = errors.Join(closeErr, ptmx.Close())
+	<-copied
+
+	if interactErr != nil {
+		return interactErr
+	}
+
+	if closeErr != nil && execErr == nil {
+		return fmt.Errorf("close pty: %w", closeErr)
+	}
+
+	return execErr
+}
+
+// forwardInput copies the presenter input to the pseudo-terminal until stop
+// gets closed. The input is polled, so that no keypress gets consumed after
+// the command finished.
+func forwardInput(in, ptmx *os.File, stop <-chan struct{}) {
+	fd := int(in.Fd())
+	buf := make([]byte, ptyInputBufferSize)
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		ready, err := inputReady(in)
+		if err != nil {
+			return
+		}
+
+		if !ready {
+			continue
+		}
+
+		read, err := unix.Read(fd, buf)
+		if err != nil || read <= 0 {
+			return
+		}
+
+		if _, err := ptmx.Write(buf[:read]); err != nil {
+			return
+		}
+	}
+}
@@ -2,17 +2,18 @@ package demo
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
 	"os"
-	"os/exec"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
-	"github.com/fatih/color"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v3"
 	"golang.org/x/term"
@@ -30,6 +31,7 @@ var (
 // runs.
 type Run struct {
 	title       string
+	heading     string
 	description []string
 	steps       []step
 	out         io.Writer
@@ -40,12 +42,59 @@ type Run struct {
 	cleanup     func() error
 	dir         string
 	env         []string
+	useSession  bool
+	session     *session
+	report      *RunResult
+	cassette    *Cassette
+	cassetteRun *CassetteRun
+	replay      *Cassette
+	player      *cassettePlayer
+	executor    Executor
+	vars        map[string]string
+	data        map[string]any
+	background  []*backgroundProcess
+	usePTY      bool
+	control     *RemoteControl
+	pending     chan inputResult
+	inputClosed bool
+	notes       []string
+	notesServer *NotesServer
+	syntaxTheme *SyntaxTheme
+	prompt      string
+	exitCode    int
+	observers   []Observer
+
+	// requirements are checked before the setup of the run.
+	requirements []Requirement
+
+	// cleanups are the step cleanup functions of the current execution and
+	// registered contains the numbers of the steps which registered them.
+	cleanups   []func(context.Context) error
+	registered map[int]struct{}
+
+	// demoObservers are the observers inherited from the demo, which get
+	// replaced whenever the demo runs.
+	demoObservers []Observer
 }
 
 type step struct {
 	text, command         []string
 	canFail, isBreakPoint bool
 	dir                   string
+	matcher               Matcher
+	timeout               time.Duration
+	retries               int
+	backoff               time.Duration
+	eventually, interval  time.Duration
+	capture               *capture
+	background            bool
+	hideOutput            bool
+	probe                 Probe
+	pty                   bool
+	script                []Interaction
+	notes                 []string
+	setup                 []stepHook
+	cleanup               []stepHook
 }
 
 // Options specify the run options.
@@ -66,10 +115,12 @@ type Options struct {
 	Shell            string
 	TypewriterSpeed  int
 
-	// Cached color functions to avoid repeated conditionals
-	cyanSprintf  func(format string, a ...interface{}) string
-	whiteSprintf func(format string, a ...interface{}) string
-	greenSprintf func(format string, a ...interface{}) string
+	// Data contains additional values for the template placeholders, which
+	// take precedence over the ones set via Run.SetData.
+	Data map[string]any
+
+	// Theme defines the styles of the output, which defaults to DefaultTheme.
+	Theme *Theme
 }
 
 func emptyFn() error { return nil }
@@ -90,8 +141,12 @@ func NewRun(title string, description ...string) *Run {
 }
 
 // optionsFrom creates a new set of options from the provided command.
-func optionsFrom(ctx context.Context, cmd *cli.Command) Options {
-	noColor := cmd.Bool(FlagNoColor)
+func optionsFrom(ctx context.Context, cmd *cli.Command) (Options, error) {
+	theme, err := themeFrom(cmd.String(FlagTheme))
+	if err != nil {
+		return Options{}, err
+	}
+
 	opts := Options{
 		Context:          ctx,
 		AutoTimeout:      cmd.Duration(FlagAutoTimeout),
@@ -100,28 +155,20 @@ func optionsFrom(ctx context.Context, cmd *cli.Command) Options {
 		ContinueOnError:  cmd.Bool(FlagContinueOnError),
 		HideDescriptions: cmd.Bool(FlagHideDescriptions),
 		DryRun:           cmd.Bool(FlagDryRun),
-		NoColor:          noColor,
+		NoColor:          cmd.Bool(FlagNoColor),
 		Immediate:        cmd.Bool(FlagImmediate),
 		SkipSteps:        cmd.Int(FlagSkipSteps),
 		Shell:            cmd.String(FlagShell),
 		TypewriterSpeed:  cmd.Int(FlagTypewriterSpeed),
+		Data:             map[string]any{},
+		Theme:            theme,
 	}
 
-	initColorFunctions(&opts)
-
-	return opts
-}
-
-func initColorFunctions(opts *Options) {
-	if opts.NoColor {
-		opts.cyanSprintf = fmt.Sprintf
-		opts.whiteSprintf = fmt.Sprintf
-		opts.greenSprintf = fmt.Sprintf
-	} else {
-		opts.cyanSprintf = color.CyanString
-		opts.whiteSprintf = color.New(color.FgWhite, color.Faint).SprintfFunc()
-		opts.greenSprintf = color.GreenString
+	for key, value := range cmd.StringMap(FlagSet) {
+		opts.Data[key] = value
 	}
+
+	return opts, nil
 }
 
 // S is a short-hand for converting string slice syntaxes.
@@ -162,7 +209,9 @@ func (r *Run) Setup(setupFn func() error) {
 	r.setup = setupFn
 }
 
-// Cleanup sets the cleanup function called after this run.
+// Cleanup sets the cleanup function called after this run, even if its
+// setup function failed. It is not called if the requirements of the run are
+// not met, because the setup has not been started then.
 func (r *Run) Cleanup(cleanupFn func() error) {
 	r.cleanup = cleanupFn
 }
@@ -179,14 +228,45 @@ func (r *Run) SetEnv(env ...string) {
 	r.env = append(r.env, env...)
 }
 
+// SetExecutor replaces the executor used for running the commands of all
+// steps. It defaults to a LocalExecutor using the configured shell. The
+// executor is not used in session mode.
+func (r *Run) SetExecutor(executor Executor) error {
+	if executor == nil {
+		return errExecutorNil
+	}
+
+	r.executor = executor
+
+	return nil
+}
+
+// SetSession enables or disables the session mode for this run. In session
+// mode, all steps are executed within a single long-lived shell process, so
+// that environment variables, working directory changes, shell functions and
+// aliases are preserved between steps, like in a real terminal session.
+//
+// Commands in session mode are passed to eval of the configured shell and do
+// not read from the run input. A command calling exit ends the session.
+func (r *Run) SetSession(enabled bool) {
+	r.useSession = enabled
+}
+
 // Step creates a new step on the provided run.
-func (r *Run) Step(text, command []string) {
-	r.steps = append(r.steps, step{text: text, command: command})
+func (r *Run) Step(text, command []string, opts ...StepOption) {
+	r.steps = append(r.steps, step{text: text, command: command}.applyOptions(opts))
 }
 
 // StepCanFail creates a new step which can fail on execution.
-func (r *Run) StepCanFail(text, command []string) {
-	r.steps = append(r.steps, step{text: text, command: command, canFail: true})
+func (r *Run) StepCanFail(text, command []string, opts ...StepOption) {
+	r.steps = append(r.steps, step{text: text, command: command, canFail: true}.applyOptions(opts))
+}
+
+// StepExpect creates a new step which fails if the combined stdout and
+// stderr of the command does not satisfy the provided matcher. The output is
+// still streamed to the run output while being captured.
+func (r *Run) StepExpect(text, command []string, matcher Matcher, opts ...StepOption) {
+	r.steps = append(r.steps, step{text: text, command: command, matcher: matcher}.applyOptions(opts))
 }
 
 // Chdir creates a step that changes the working directory for subsequent steps.
@@ -201,7 +281,10 @@ func (r *Run) BreakPoint() {
 
 // Run executes the run in the provided CLI context.
 func (r *Run) Run(ctx context.Context, cmd *cli.Command) error {
-	opts := optionsFrom(ctx, cmd)
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
 
 	return r.RunWithOptions(&opts)
 }
@@ -213,48 +296,132 @@ func (r *Run) RunWithOptions(opts *Options) error {
 	}
 
 	if opts.Shell == "" {
-		opts.Shell = "bash"
+		opts.Shell = defaultShell
 	}
 
 	if opts.TypewriterSpeed == 0 {
 		opts.TypewriterSpeed = DefaultTypewriterSpeed
 	}
 
-	if opts.cyanSprintf == nil {
-		initColorFunctions(opts)
+	if opts.Theme == nil {
+		opts.Theme = DefaultTheme()
+	}
+
+	r.options = *opts
+	r.vars = map[string]string{}
+	r.heading = r.expandedTitle()
+
+	// Requirements are irrelevant if no command gets executed.
+	if !opts.DryRun && r.replay == nil {
+		if err := r.requirementsError(opts.Context); err != nil {
+			return err
+		}
 	}
 
+	r.cleanups = nil
+	r.registered = map[int]struct{}{}
+
+	// A partly finished setup needs to be cleaned up as well.
 	if err := r.setup(); err != nil {
-		return err
+		return errors.Join(err, r.finish())
 	}
 
-	r.options = *opts
+	r.observe(Event{Kind: EventRunStarted})
+
+	err := r.runPrepared()
+
+	// Cleanups run on success, failure, interruption and quit.
+	if cleanupErr := r.finish(); cleanupErr != nil {
+		if err == nil {
+			err = cleanupErr
+		} else {
+			err = errors.Join(err, cleanupErr)
+		}
+	}
+
+	r.observe(Event{Kind: EventRunFinished, Err: err})
+
+	return err
+}
+
+// runPrepared executes the run after its setup and options have been
+// applied.
+func (r *Run) runPrepared() error {
+	if r.cassette != nil {
+		r.cassetteRun = r.cassette.reset(r.title)
+	}
+
+	if r.replay != nil {
+		r.player = &cassettePlayer{run: r.replay.run(r.title)}
+	}
+
+	if r.hasBackgroundSteps() {
+		// Background processes write concurrently to the output.
+		out := r.out
+		r.out = &lockedWriter{w: out}
+
+		defer func() {
+			r.stopAllBackground()
+			r.out = out
+		}()
+	}
+
+	if r.useSession && !r.options.DryRun && r.player == nil {
+		sess, err := startSession(r)
+		if err != nil {
+			return err
+		}
+
+		r.session = sess
+
+		defer func() {
+			_ = sess.close()
+			r.session = nil
+		}()
+	}
 
 	if err := r.printTitleAndDescription(); err != nil {
 		return err
 	}
 
+	return r.runSteps()
+}
+
+// runSteps executes all steps and handles the navigation of the presenter.
+func (r *Run) runSteps() error {
 	visibleSteps := r.countVisibleSteps()
-	current := 0
+	positions := make([]int, 0, visibleSteps)
+	numbers := make([]int, len(r.steps))
+
+	for i, s := range r.steps {
+		if s.dir == "" {
+			positions = append(positions, i)
+			numbers[i] = len(positions)
+		}
+	}
+
+	workDir := r.dir
+	skip := r.options.SkipSteps
+
+	for i := 0; i < len(r.steps); {
+		s := r.steps[i]
 
-	for _, s := range r.steps {
 		// Always apply Chdir steps, even when skipped
 		if s.dir != "" {
-			r.dir = s.dir
-
-			if !r.options.HideDescriptions {
-				cdStr := r.options.greenSprintf("> cd %s", s.dir)
-				if err := write(r.out, cdStr+"\n"); err != nil {
-					return err
-				}
+			if err := r.changeDir(s.dir); err != nil {
+				return err
 			}
 
+			i++
+
 			continue
 		}
 
-		current++
+		current := numbers[i]
+
+		if skip >= current {
+			i++
 
-		if r.options.SkipSteps >= current {
 			continue
 		}
 
@@ -262,21 +429,117 @@ func (r *Run) RunWithOptions(opts *Options) error {
 			s.canFail = true
 		}
 
-		if err := s.run(r, current, visibleSteps); err != nil {
+		nav, err := s.run(r, current, visibleSteps)
+		if err != nil {
+			return err
+		}
+
+		switch nav.kind {
+		case navNext:
+			i++
+		case navQuit:
+			r.stopAllBackground()
+
+			return ErrQuit
+		case navBack, navRerun, navJump:
+			skip = 0
+			i = positions[nav.target(current, visibleSteps)-1]
+
+			if err := r.restoreDir(workDir, i); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.stopAllBackground()
+
+	return nil
+}
+
+// restoreDir silently applies all Chdir steps before the provided step
+// index after the presenter navigated to it.
+func (r *Run) restoreDir(workDir string, index int) error {
+	dir := workDir
+
+	for _, s := range r.steps[:index] {
+		if s.dir != "" {
+			dir = s.dir
+		}
+	}
+
+	if dir != workDir {
+		expanded, err := r.expandDir(dir)
+		if err != nil {
+			return err
+		}
+
+		dir = expanded
+	}
+
+	if dir == r.dir {
+		return nil
+	}
+
+	r.dir = dir
+
+	if r.session != nil && dir != "" {
+		if err := r.session.run(r.options.Context, "cd "+shellQuote(dir), io.Discard); err != nil {
+			return fmt.Errorf("change session directory: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Run) changeDir(dir string) error {
+	dir, err := r.expandDir(dir)
+	if err != nil {
+		return err
+	}
+
+	if !r.options.HideDescriptions {
+		prompt, err := r.renderPrompt(0)
+		if err != nil {
+			return err
+		}
+
+		if err := write(r.out, prompt+r.highlight("cd "+dir)+"\n"); err != nil {
 			return err
 		}
 	}
 
-	return r.cleanup()
+	r.dir = dir
+
+	if r.session != nil {
+		if err := r.session.run(r.options.Context, "cd "+shellQuote(dir), r.out); err != nil {
+			return fmt.Errorf("change session directory: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func (r *Run) printTitleAndDescription() error {
-	if err := write(r.out, r.options.cyanSprintf("%s\n", r.title)); err != nil {
+	data := r.templateData(0)
+
+	title, err := r.expand(r.title, data)
+	if err != nil {
+		return fmt.Errorf("unable to print title: %w", err)
+	}
+
+	description, err := r.expandAll(r.description, data)
+	if err != nil {
+		return fmt.Errorf("unable to print description: %w", err)
+	}
+
+	theme := r.theme()
+
+	if err := write(r.out, r.style(theme.Title, "%s\n", title)); err != nil {
 		return err
 	}
 
-	for range r.title {
-		if err := write(r.out, r.options.cyanSprintf("=")); err != nil {
+	for range title {
+		if err := write(r.out, r.style(theme.Underline, "=")); err != nil {
 			return err
 		}
 	}
@@ -286,9 +549,9 @@ func (r *Run) printTitleAndDescription() error {
 	}
 
 	if !r.options.HideDescriptions {
-		for _, d := range r.description {
+		for _, d := range description {
 			if err := write(
-				r.out, r.options.whiteSprintf("%s\n", d),
+				r.out, r.style(theme.Description, "%s\n", d),
 			); err != nil {
 				return err
 			}
@@ -323,30 +586,49 @@ func (r *Run) countVisibleSteps() int {
 	return count
 }
 
-func (s *step) run(r *Run, current, maximum int) error {
-	if err := s.waitOrSleep(r); err != nil {
-		return fmt.Errorf("unable to run step: %w", err)
+func (s *step) run(r *Run, current, maximum int) (navAction, error) {
+	r.setControlStep(current, maximum)
+	r.setNotesStep(current, maximum)
+
+	nav, err := s.waitOrSleep(r)
+	if err != nil {
+		return nav, fmt.Errorf("unable to run step: %w", err)
+	}
+
+	if nav.kind != navNext {
+		return nav, nil
+	}
+
+	if err := s.expand(r, current); err != nil {
+		return nav, fmt.Errorf("unable to run step %d: %w", current, err)
 	}
 
 	if len(s.text) > 0 && !r.options.HideDescriptions {
 		if err := s.echo(r, current, maximum); err != nil {
-			return err
+			return nav, err
 		}
 	}
 
 	if s.isBreakPoint {
-		return s.wait(r)
+		if r.options.BreakPoint {
+			r.observe(Event{Kind: EventBreakPoint, Step: current})
+		}
+
+		return nav, s.wait(r)
 	}
 
 	if len(s.command) > 0 {
-		return s.execute(r)
+		return s.execute(r, current)
 	}
 
-	return nil
+	r.observe(Event{Kind: EventStepTyped, Step: current, Text: s.text})
+
+	return nav, nil
 }
 
 func (s *step) echo(r *Run, current, maximum int) error {
 	prepared := make([]string, len(s.text))
+	theme := r.theme()
 
 	for i, x := range s.text {
 		if i == len(s.text)-1 {
@@ -355,48 +637,61 @@ func (s *step) echo(r *Run, current, maximum int) error {
 				colon = ""
 			}
 
-			prepared[i] = r.options.whiteSprintf(
-				"# %s [%d/%d]%s\n",
-				x, current, maximum, colon,
-			)
+			prepared[i] = r.style(theme.Description, "# %s ", x) +
+				r.style(theme.StepCounter, "[%d/%d]", current, maximum) +
+				r.style(theme.Description, "%s\n", colon)
 		} else {
-			prepared[i] = r.options.whiteSprintf("# %s", x)
+			prepared[i] = r.style(theme.Description, "# %s", x)
 		}
 	}
 
 	return s.print(r, prepared...)
 }
 
-func (s *step) execute(r *Run) error {
-	joinedCommand := strings.Join(s.command, " ")
-	//nolint:gosec // we purposefully run user-provided code
-	cmd := exec.CommandContext(r.options.Context, r.options.Shell, "-c", joinedCommand)
-
-	cmd.Stderr = r.out
-	cmd.Stdout = r.out
-	cmd.Stdin = r.inFile
-	cmd.Dir = r.dir
+func (s *step) execute(r *Run, current int) (navAction, error) {
+	displayCommand := strings.Join(s.command, " \\\n    ")
+	prompt, err := r.renderPrompt(current)
+	if err != nil {
+		return navAction{}, fmt.Errorf("unable to execute step: %w", err)
+	}
 
-	if len(r.env) > 0 {
-		cmd.Env = append(os.Environ(), r.env...)
+	if err := s.print(r, prompt+r.highlight(displayCommand)); err != nil {
+		return navAction{}, err
 	}
 
-	displayCommand := strings.Join(s.command, " \\\n    ")
-	cmdString := r.options.greenSprintf("> %s", displayCommand)
+	r.observe(Event{Kind: EventStepTyped, Step: current, Text: s.text, Command: s.commandLine()})
 
-	if err := s.print(r, cmdString); err != nil {
-		return err
+	nav, err := s.waitOrSleep(r)
+	if err != nil {
+		return nav, fmt.Errorf("unable to execute step: %w", err)
 	}
 
-	if err := s.waitOrSleep(r); err != nil {
-		return fmt.Errorf("unable to execute step: %w", err)
+	if nav.kind != navNext || r.options.DryRun {
+		return nav, nil
 	}
 
-	if r.options.DryRun {
-		return nil
+	if err := s.prepare(r, current); err != nil {
+		return nav, err
+	}
+
+	if s.background {
+		return nav, s.startBackground(r, current)
 	}
 
-	err := cmd.Run()
+	return nav, s.executeCommand(r, current)
+}
+
+func (s *step) executeCommand(r *Run, current int) error {
+	output := &bytes.Buffer{}
+	r.observe(Event{Kind: EventCommandStarted, Step: current, Text: s.text, Command: s.commandLine()})
+
+	start := time.Now()
+	err := s.runAttempts(r, output)
+	r.exitCode = exitCode(err)
+
+	duration := time.Since(start)
+	s.observeCommandFinished(r, current, duration, output.String(), err)
+	r.record(s, current, duration, output.String(), err)
 
 	if s.canFail {
 		return nil
@@ -413,6 +708,106 @@ func (s *step) execute(r *Run) error {
 	return nil
 }
 
+// runCommand executes, records or replays the command of the step and
+// writes its combined output to out. If stdout is not nil, the standard
+// output is additionally written to it, which receives the combined output in
+// session mode, for a pseudo-terminal and on replay.
+func (r *Run) runCommand(ctx context.Context, s *step, out, stdout io.Writer) error {
+	switch {
+	case r.player != nil:
+		return r.player.play(ctx, s.commandLine(), teeWriter(out, stdout))
+	case r.cassetteRun != nil:
+		return r.recordCommand(ctx, r.cassetteRun, s, out, stdout)
+	default:
+		return r.execCommand(ctx, s, out, stdout)
+	}
+}
+
+// execCommand executes the command of the step either within the session or
+// in a new shell process, which is optionally attached to a pseudo-terminal,
+// and writes its combined output to out and its standard output to stdout.
+func (r *Run) execCommand(ctx context.Context, s *step, out, stdout io.Writer) error {
+	command := s.commandLine()
+	tty := r.usesPTY(s)
+
+	switch {
+	case tty && r.session != nil:
+		return errPTYSession
+	case tty:
+		return r.execPTY(ctx, command, s.script, teeWriter(out, stdout))
+	case r.session != nil:
+		return r.session.run(ctx, command, teeWriter(out, stdout))
+	}
+
+	execution := &Execution{
+		Command: command,
+		Dir:     r.dir,
+		Env:     r.env,
+		Stdout:  out,
+		Stderr:  out,
+	}
+
+	if stdout != nil {
+		// Both outputs get copied concurrently if they are different.
+		locked := &lockedWriter{w: out}
+		execution.Stdout = io.MultiWriter(locked, stdout)
+		execution.Stderr = locked
+	}
+
+	if r.inFile != nil {
+		execution.Stdin = r.inFile
+	}
+
+	//nolint:wrapcheck // wrapped by the caller
+	return r.executorOrDefault().Execute(ctx, execution)
+}
+
+// executorOrDefault returns the configured executor or a LocalExecutor.
+func (r *Run) executorOrDefault() Executor {
+	if r.executor == nil {
+		return &LocalExecutor{Shell: r.options.Shell}
+	}
+
+	return r.executor
+}
+
+// localExecution returns true if the commands of the run get executed on the
+// machine of the presenter, which is not the case for other executors than
+// the LocalExecutor.
+func (r *Run) localExecution() bool {
+	_, ok := r.executorOrDefault().(*LocalExecutor)
+
+	return ok
+}
+
+// teeWriter returns a writer which writes to out and the optional stdout.
+func teeWriter(out, stdout io.Writer) io.Writer {
+	if stdout == nil {
+		return out
+	}
+
+	return io.MultiWriter(out, stdout)
+}
+
+// lockedWriter serializes concurrent writes to the underlying writer.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	//nolint:wrapcheck // the writer is transparent
+	return l.w.Write(p)
+}
+
+// Unwrap returns the underlying writer, which may be a terminal.
+func (l *lockedWriter) Unwrap() io.Writer {
+	return l.w
+}
+
 func (s *step) print(r *Run, msg ...string) error {
 	for _, m := range msg {
 		if r.options.Immediate {
@@ -437,9 +832,22 @@ func (s *step) typewrite(r *Run, m string) error {
 	restore, raw := r.enterRawMode()
 	defer restore()
 
-	for _, c := range m {
-		//nolint:gosec // random sleep timing for visual effect, not security-sensitive
-		time.Sleep(time.Duration(rand.IntN(r.options.TypewriterSpeed)) * time.Millisecond)
+	for i := 0; i < len(m); {
+		// Escape sequences are written at once, because they are invisible.
+		if seq := escapeSequence(m[i:]); seq != "" {
+			if err := write(r.out, seq); err != nil {
+				return err
+			}
+
+			i += len(seq)
+
+			continue
+		}
+
+		time.Sleep(r.typewriterDelay())
+
+		c, size := utf8.DecodeRuneInString(m[i:])
+		i += size
 
 		ch := string(c)
 		if raw && c == '\n' {
@@ -454,11 +862,32 @@ func (s *step) typewrite(r *Run, m string) error {
 	return nil
 }
 
-func (s *step) waitOrSleep(r *Run) error {
-	if r.options.Auto {
-		time.Sleep(r.options.AutoTimeout)
+// escapeSequence returns the ANSI control sequence at the beginning of the
+// text, if any.
+func escapeSequence(text string) string {
+	if !strings.HasPrefix(text, "\x1b[") {
+		return ""
+	}
 
-		return nil
+	for i := 2; i < len(text); i++ {
+		// The final byte of control sequences is in the range of '@' to '~'.
+		if text[i] >= '@' && text[i] <= '~' {
+			return text[:i+1]
+		}
+	}
+
+	return text
+}
+
+// typewriterDelay returns a random delay between two typed characters.
+func (r *Run) typewriterDelay() time.Duration {
+	//nolint:gosec // random sleep timing for visual effect, not security-sensitive
+	return time.Duration(rand.IntN(r.options.TypewriterSpeed)) * time.Millisecond
+}
+
+func (s *step) waitOrSleep(r *Run) (navAction, error) {
+	if r.options.Auto {
+		return r.awaitTimeout()
 	}
 
 	restore, raw := r.enterRawMode()
@@ -466,13 +895,14 @@ func (s *step) waitOrSleep(r *Run) error {
 	if err := write(r.out, "\u2026"); err != nil {
 		restore()
 
-		return err
+		return navAction{}, err
 	}
 
-	if err := r.readInput(raw); err != nil {
+	nav, err := r.awaitNavigation(raw)
+	if err != nil {
 		restore()
 
-		return err
+		return navAction{}, err
 	}
 
 	restore()
@@ -480,10 +910,10 @@ func (s *step) waitOrSleep(r *Run) error {
 	if raw {
 		// In raw mode, Enter doesn't produce a visible newline,
 		// so just clear the prompt on the current line.
-		return write(r.out, "\r\x1b[K")
+		return nav, write(r.out, "\r\x1b[K")
 	}
 
-	return moveCursorUp(r.out)
+	return nav, moveCursorUp(r.out)
 }
 
 func (s *step) wait(r *Run) error {
@@ -493,13 +923,13 @@ func (s *step) wait(r *Run) error {
 
 	restore, raw := r.enterRawMode()
 
-	if err := write(r.out, "bp"); err != nil {
+	if err := write(r.out, r.style(r.theme().BreakPoint, "bp")); err != nil {
 		restore()
 
 		return err
 	}
 
-	if err := r.readInput(raw); err != nil {
+	if _, err := r.awaitNavigation(raw); err != nil {
 		restore()
 
 		return err
@@ -517,11 +947,16 @@ func (s *step) wait(r *Run) error {
 // enterRawMode puts the terminal into raw mode if stdin is a terminal.
 // It returns a restore function and whether raw mode was activated.
 func (r *Run) enterRawMode() (func(), bool) {
-	if r.inFile == nil {
+	return enterRawMode(r.inFile)
+}
+
+// enterRawMode puts the terminal into raw mode if the file is a terminal.
+func enterRawMode(f *os.File) (func(), bool) {
+	if f == nil {
 		return func() {}, false
 	}
 
-	fd := r.inFile.Fd()
+	fd := f.Fd()
 	if !isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd) {
 		return func() {}, false
 	}
@@ -536,23 +971,19 @@ func (r *Run) enterRawMode() (func(), bool) {
 	return func() { _ = term.Restore(intFd, oldState) }, true
 }
 
-// readInput reads input, using single-byte read in raw mode or line read otherwise.
-func (r *Run) readInput(raw bool) error {
+// readInput reads the navigation of the presenter, using single-byte read
+// in raw mode or line read otherwise.
+func (r *Run) readInput(raw bool) (navAction, error) {
 	if raw {
-		_, err := r.in.ReadByte()
-		if err != nil {
-			return fmt.Errorf("unable to read keypress: %w", err)
-		}
-
-		return nil
+		return r.readRawNavigation()
 	}
 
-	_, err := r.in.ReadBytes('\n')
+	line, err := r.in.ReadString('\n')
 	if err != nil {
-		return fmt.Errorf("unable to read newline: %w", err)
+		return navAction{}, fmt.Errorf("unable to read newline: %w", err)
 	}
 
-	return nil
+	return parseNavigation(line), nil
 }
 
 func moveCursorUp(w io.Writer) error {
@@ -564,6 +995,16 @@ func moveCursorUp(w io.Writer) error {
 }
 
 func isTerminal(w io.Writer) bool {
+	// Wrapping writers like the lockedWriter are transparent.
+	for {
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+
+		w = u.Unwrap()
+	}
+
 	if f, ok := w.(*os.File); ok {
 		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 	}
@@ -318,6 +318,28 @@ var _ = Describe("Run", func() {
 		Expect(err.Error()).To(ContainSubstring("setup failed"))
 	})
 
+	It("should clean up when setup returns error", func() {
+		// Given
+		cleanupCalled := false
+
+		sut.Setup(func() error {
+			return errSetupFailed
+		})
+		sut.Cleanup(func() error {
+			cleanupCalled = true
+
+			return errCleanupFailed
+		})
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(errSetupFailed))
+		Expect(err).To(MatchError(errCleanupFailed))
+		Expect(cleanupCalled).To(BeTrue())
+	})
+
 	It("should fail when cleanup returns error", func() {
 		// Given
 		sut.Cleanup(func() error {
@@ -550,6 +572,43 @@ var _ = Describe("Run", func() {
 		Expect(out.String()).ToNot(ContainSubstring("cd /tmp"))
 	})
 
+	It("should succeed to run a step with expected output", func() {
+		// Given
+		sut.StepExpect(demo.S("Expect test"), demo.S("echo expected"), demo.OutputEquals("expected"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("expected\n"))
+	})
+
+	It("should fail to run a step with unexpected output", func() {
+		// Given
+		sut.StepExpect(demo.S("Expect test"), demo.S("echo actual >&2"), demo.OutputContains("expected"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("output mismatch")))
+		Expect(out.String()).To(ContainSubstring("actual"))
+	})
+
+	It("should succeed to run a step with unexpected output which can fail", func() {
+		// Given
+		opts.ContinueOnError = true
+
+		sut.StepExpect(demo.S("Expect test"), demo.S("echo actual"), demo.OutputMatches("^expected$"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
 	It("should properly handle empty title", func() {
 		// Given
 		emptySut := demo.NewRun("")
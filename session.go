@@ -0,0 +1,273 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// errSessionClosed is the error returned if the session shell exited, for
+// example because a command called exit.
+var errSessionClosed = errors.New("session closed: the shell exited")
+
+// exitStatusError is the error returned if a command, which has not been
+// executed as separate process, exits non-zero.
+type exitStatusError struct {
+	code int
+}
+
+func (e *exitStatusError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// ExitCode returns the exit code of the command, like exec.ExitError does.
+func (e *exitStatusError) ExitCode() int {
+	return e.code
+}
+
+// exitCode returns the exit code of a command error.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+const (
+	// sessionMarkerBytes is the amount of random bytes used for the marker
+	// which separates the output of subsequent commands.
+	sessionMarkerBytes = 8
+
+	// sessionReadBufferSize is the size of the buffer for reading the shell output.
+	sessionReadBufferSize = 4096
+)
+
+// session is a long-lived shell process which executes the commands of
+// multiple steps, so that the shell state is preserved between them.
+//
+// The end of every command is detected by printing a random marker together
+// with the exit code of the command, which gets stripped from the output.
+type session struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	id      string
+	marker  []byte
+	results chan int
+	closed  chan struct{}
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// startSession starts a new shell session for the run.
+func startSession(r *Run) (*session, error) {
+	id := make([]byte, sessionMarkerBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("generate session marker: %w", err)
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create session pipe: %w", err)
+	}
+
+	//nolint:gosec // we purposefully run user-provided code
+	cmd := exec.CommandContext(r.options.Context, r.options.Shell)
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	cmd.Dir = r.dir
+
+	if len(r.env) > 0 {
+		cmd.Env = append(os.Environ(), r.env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("create session input: %w", err), pr.Close(), pw.Close())
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Join(fmt.Errorf("start session shell: %w", err), pr.Close(), pw.Close())
+	}
+
+	// The write end is now owned by the shell process.
+	if err := pw.Close(); err != nil {
+		return nil, fmt.Errorf("close session pipe: %w", err)
+	}
+
+	markerID := "__demo_" + hex.EncodeToString(id)
+	s := &session{
+		cmd:     cmd,
+		stdin:   stdin,
+		id:      markerID,
+		marker:  []byte("\n" + markerID + ":"),
+		results: make(chan int, 1),
+		closed:  make(chan struct{}),
+		out:     io.Discard,
+	}
+
+	go s.read(pr)
+
+	// Non-interactive bash does not expand aliases by default, other shells
+	// will silently ignore this.
+	if err := write(stdin, "shopt -s expand_aliases >/dev/null 2>&1\n"); err != nil {
+		return nil, errors.Join(err, s.close())
+	}
+
+	return s, nil
+}
+
+// run executes the command within the session and streams its output to out.
+func (s *session) run(ctx context.Context, command string, out io.Writer) error {
+	s.mu.Lock()
+	s.out = out
+	s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+		return errSessionClosed
+	default:
+	}
+
+	// Eval keeps the command in the current shell, while stdin is redirected
+	// to not consume the subsequent commands of the session. Quoting the
+	// command ensures that syntax errors cannot swallow the marker.
+	input := fmt.Sprintf(
+		"eval %s </dev/null\nprintf '\\n%%s:%%d\\n' %s \"$?\"\n", shellQuote(command), s.id,
+	)
+
+	if err := write(s.stdin, input); err != nil {
+		return fmt.Errorf("%w: send command: %w", errSessionClosed, err)
+	}
+
+	select {
+	case code, ok := <-s.results:
+		if !ok {
+			return errSessionClosed
+		}
+
+		if code != 0 {
+			return &exitStatusError{code: code}
+		}
+
+		return nil
+	case <-ctx.Done():
+		// The aborted command would still consume the subsequent commands,
+		// so the session cannot be used any more.
+		_ = s.cmd.Process.Kill()
+
+		return fmt.Errorf("wait for session command: %w", ctx.Err())
+	}
+}
+
+// close terminates the session shell.
+func (s *session) close() error {
+	if err := s.stdin.Close(); err != nil {
+		return fmt.Errorf("close session input: %w", err)
+	}
+
+	// The exit status of the shell itself is irrelevant at this point.
+	_ = s.cmd.Wait()
+
+	return nil
+}
+
+// read forwards the shell output and extracts the command exit codes.
+func (s *session) read(r io.ReadCloser) {
+	defer close(s.closed)
+	defer close(s.results)
+	defer r.Close()
+
+	buf := make([]byte, sessionReadBufferSize)
+
+	var pending []byte
+
+	for {
+		n, err := r.Read(buf)
+		pending = s.process(append(pending, buf[:n]...))
+
+		if err != nil {
+			s.forward(pending)
+
+			return
+		}
+	}
+}
+
+// process forwards all complete output and returns the remaining data,
+// which may contain the beginning of a marker.
+func (s *session) process(data []byte) []byte {
+	for {
+		idx := bytes.Index(data, s.marker)
+		if idx < 0 {
+			break
+		}
+
+		codeStart := idx + len(s.marker)
+
+		end := bytes.IndexByte(data[codeStart:], '\n')
+		if end < 0 {
+			s.forward(data[:idx])
+
+			return append([]byte(nil), data[idx:]...)
+		}
+
+		code, err := strconv.Atoi(string(data[codeStart : codeStart+end]))
+		if err != nil {
+			code = -1
+		}
+
+		s.forward(data[:idx])
+		s.results <- code
+
+		data = data[codeStart+end+1:]
+	}
+
+	keep := partialSuffix(data, s.marker)
+	s.forward(data[:len(data)-keep])
+
+	return append([]byte(nil), data[len(data)-keep:]...)
+}
+
+func (s *session) forward(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _ = s.out.Write(data)
+}
+
+// partialSuffix returns the length of the longest suffix of data which is a
+// prefix of marker.
+func partialSuffix(data, marker []byte) int {
+	for n := min(len(data), len(marker)-1); n > 0; n-- {
+		if bytes.Equal(marker[:n], data[len(data)-n:]) {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// shellQuote quotes the provided string to be used as single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
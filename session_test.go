@@ -0,0 +1,169 @@
+package demo_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Session", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Session")
+		sut.SetSession(true)
+
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should preserve environment variables between steps", func() {
+		// Given
+		sut.Step(nil, demo.S("export SESSION_VAR=persisted"))
+		sut.Step(nil, demo.S("echo value=$SESSION_VAR"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("value=persisted"))
+	})
+
+	It("should preserve the working directory between steps", func() {
+		// Given
+		sut.Step(nil, demo.S("cd /tmp"))
+		sut.Step(nil, demo.S("echo dir=$(pwd)"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("dir=/tmp"))
+	})
+
+	It("should preserve functions and aliases between steps", func() {
+		// Given
+		sut.Step(nil, demo.S("greet() { echo \"hello $1\"; }"))
+		sut.Step(nil, demo.S("alias shout='echo LOUD'"))
+		sut.Step(nil, demo.S("greet session"))
+		sut.Step(nil, demo.S("shout"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("hello session"))
+		Expect(out.String()).To(ContainSubstring("LOUD"))
+	})
+
+	It("should apply Chdir and SetWorkDir to the session", func() {
+		// Given
+		sut.SetWorkDir("/")
+		sut.Step(nil, demo.S("echo start=$(pwd)"))
+		sut.Chdir("/tmp")
+		sut.Step(nil, demo.S("echo after=$(pwd)"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("start=/\n"))
+		Expect(out.String()).To(ContainSubstring("after=/tmp"))
+	})
+
+	It("should strip the completion markers from the output", func() {
+		// Given
+		sut.Step(nil, demo.S("printf 'no newline'"))
+		sut.Step(nil, demo.S("echo next"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("no newline"))
+		Expect(out.String()).ToNot(ContainSubstring("__demo_"))
+	})
+
+	It("should fail if a step exits non-zero", func() {
+		// Given
+		sut.Step(nil, demo.S("false"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+	})
+
+	It("should continue after a failing step which can fail", func() {
+		// Given
+		sut.StepCanFail(nil, demo.S("(exit 3)"))
+		sut.StepExpect(nil, demo.S("echo still alive"), demo.OutputEquals("still alive"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should not consume the session input by commands", func() {
+		// Given
+		sut.Step(nil, demo.S("cat"))
+		sut.Step(nil, demo.S("echo after cat"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("after cat"))
+	})
+
+	It("should fail on syntax errors and keep the session usable", func() {
+		// Given
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		opts.Context = ctx
+
+		sut.StepCanFail(nil, demo.S("echo 'unterminated"))
+		sut.StepExpect(nil, demo.S("echo still alive"), demo.OutputEquals("still alive"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ctx.Err()).ToNot(HaveOccurred())
+	})
+
+	It("should fail if the session shell exited", func() {
+		// Given
+		sut.StepCanFail(nil, demo.S("exit 3"))
+		sut.Step(nil, demo.S("echo unreachable"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("session closed")))
+		Expect(err).ToNot(MatchError(ContainSubstring("broken pipe")))
+		Expect(out.String()).ToNot(ContainSubstring("\nunreachable\n"))
+	})
+})
@@ -0,0 +1,199 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// errStepTimeout is the error returned if a step command did not finish in
+// time.
+var errStepTimeout = errors.New("step command timed out")
+
+// StepOption is an additional option for a single step, which can be passed
+// to Step, StepCanFail and StepExpect.
+type StepOption func(*step)
+
+// WithTimeout aborts every execution of the step command if it did not finish
+// within the provided duration. In session mode, a timeout terminates the
+// session shell, because the state of the aborted command is unknown.
+func WithTimeout(timeout time.Duration) StepOption {
+	return func(s *step) {
+		s.timeout = timeout
+	}
+}
+
+// WithRetries executes the step command up to retries more times if it
+// failed. The first retry waits for the provided backoff, which gets doubled
+// for every further retry.
+func WithRetries(retries int, backoff time.Duration) StepOption {
+	return func(s *step) {
+		s.retries = retries
+		s.backoff = backoff
+	}
+}
+
+// Eventually executes the step command repeatedly with the provided interval
+// until it succeeds or the timeout elapsed, for example to wait until a
+// service is available. Matchers of StepExpect are checked on every attempt.
+func Eventually(timeout, interval time.Duration) StepOption {
+	return func(s *step) {
+		s.eventually = timeout
+		s.interval = interval
+	}
+}
+
+// canFail allows the step to fail, like StepCanFail does.
+func canFail() StepOption {
+	return func(s *step) {
+		s.canFail = true
+	}
+}
+
+// withMatcher verifies the output of the step, like StepExpect does.
+func withMatcher(matcher Matcher) StepOption {
+	return func(s *step) {
+		s.matcher = matcher
+	}
+}
+
+// applyOptions applies all step options and returns the resulting step.
+func (s step) applyOptions(opts []StepOption) step {
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	return s
+}
+
+// runAttempts executes the command until it succeeds or no further attempt
+// is allowed. The output buffer contains the output of the last attempt.
+func (s *step) runAttempts(r *Run, output *bytes.Buffer) error {
+	deadline := time.Now().Add(s.eventually)
+	backoff := s.backoff
+
+	for attempt := 1; ; attempt++ {
+		output.Reset()
+
+		err := s.runAttempt(r, output)
+		if err == nil {
+			return nil
+		}
+
+		var delay time.Duration
+
+		switch {
+		case attempt <= s.retries:
+			delay = backoff
+			backoff *= 2
+		case s.eventually > 0 && time.Now().Add(s.interval).Before(deadline):
+			delay = s.interval
+		default:
+			return err
+		}
+
+		if err := write(r.out, r.style(r.theme().Error, "# %v, retrying in %s\n", err, delay)); err != nil {
+			return err
+		}
+
+		if err := sleep(r.options.Context, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// runAttempt executes the command once, respecting the step timeout, and
+// applies the matcher.
+func (s *step) runAttempt(r *Run, output *bytes.Buffer) error {
+	out := r.out
+
+	switch {
+	case s.hideOutput:
+		out = output
+	case s.matcher != nil || r.report != nil || r.observed():
+		out = io.MultiWriter(r.out, output)
+	}
+
+	ctx := r.options.Context
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var stdout io.Writer
+
+	captured := &bytes.Buffer{}
+	if s.capture != nil {
+		stdout = captured
+	}
+
+	tty := r.usesPTY(s)
+
+	err := r.styleOutput(!s.hideOutput, func() error {
+		return r.runCommand(ctx, s, out, stdout)
+	})
+
+	if tty {
+		normalizeNewlines(output)
+		normalizeNewlines(captured)
+	}
+
+	if err != nil && r.options.Context.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", errStepTimeout, s.timeout)
+	}
+
+	if err == nil && s.matcher != nil {
+		err = s.matcher.Match(output.String())
+	}
+
+	if err == nil && s.capture != nil {
+		err = s.capture.apply(r, captured.String())
+	}
+
+	return err
+}
+
+// expand renders the template placeholders of the step text and command.
+func (s *step) expand(r *Run, current int) error {
+	data := r.templateData(current)
+
+	text, err := r.expandAll(s.text, data)
+	if err != nil {
+		return err
+	}
+
+	command, err := r.expandAll(s.command, data)
+	if err != nil {
+		return err
+	}
+
+	s.text = text
+	s.command = command
+
+	return nil
+}
+
+// commandLine returns the command of the step as single line.
+func (s *step) commandLine() string {
+	return strings.Join(s.command, " ")
+}
+
+// sleep waits for the provided duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("wait for retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package demo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("StepOption", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should fail if a step exceeds its timeout", func() {
+		// Given
+		sut.Step(demo.S("Hang"), demo.S("sleep 10"), demo.WithTimeout(100*time.Millisecond))
+
+		// When
+		start := time.Now()
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("step command timed out after 100ms")))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("should continue after a timeout of a step which can fail", func() {
+		// Given
+		sut.StepCanFail(nil, demo.S("sleep 10"), demo.WithTimeout(100*time.Millisecond))
+		sut.Step(nil, demo.S("echo after"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("after\n"))
+	})
+
+	It("should fail if a session step exceeds its timeout", func() {
+		// Given
+		sut.SetSession(true)
+		sut.Step(nil, demo.S("sleep 10"), demo.WithTimeout(100*time.Millisecond))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("step command timed out")))
+	})
+
+	It("should succeed to retry a failing step", func() {
+		// Given
+		counter := filepath.Join(GinkgoT().TempDir(), "counter")
+		sut.Step(nil,
+			demo.S("echo x >> "+counter+" && test $(wc -l < "+counter+") -ge 3"),
+			demo.WithRetries(3, time.Millisecond),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("retrying in 1ms"))
+		Expect(out.String()).To(ContainSubstring("retrying in 2ms"))
+
+		content, err := os.ReadFile(counter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(content), "x")).To(Equal(3))
+	})
+
+	It("should fail if all retries failed", func() {
+		// Given
+		counter := filepath.Join(GinkgoT().TempDir(), "counter")
+		sut.Step(nil, demo.S("echo x >> "+counter+" && exit 1"), demo.WithRetries(2, 0))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+
+		content, err := os.ReadFile(counter)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(string(content), "x")).To(Equal(3))
+	})
+
+	It("should succeed if a step eventually satisfies its matcher", func() {
+		// Given
+		counter := filepath.Join(GinkgoT().TempDir(), "counter")
+		sut.StepExpect(nil,
+			demo.S("echo x >> "+counter+" && wc -l < "+counter),
+			demo.OutputEquals("4"),
+			demo.Eventually(10*time.Second, time.Millisecond),
+		)
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail if a step does not succeed eventually", func() {
+		// Given
+		sut.Step(nil, demo.S("exit 3"), demo.Eventually(50*time.Millisecond, 10*time.Millisecond))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(out.String()).To(ContainSubstring("retrying in 10ms"))
+	})
+
+	It("should report only the output of the last attempt", func() {
+		// Given
+		counter := filepath.Join(GinkgoT().TempDir(), "counter")
+		sut.Step(nil,
+			demo.S("echo x >> "+counter+" && echo attempt-$(wc -l < "+counter+") && test $(wc -l < "+counter+") -ge 2"),
+			demo.WithRetries(1, 0),
+		)
+
+		// When
+		result := sut.Verify(&opts)
+
+		// Then
+		Expect(result.Passed()).To(BeTrue())
+		Expect(result.Steps).To(HaveLen(1))
+		Expect(result.Steps[0].Output).To(Equal("attempt-2\n"))
+	})
+})
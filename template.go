@@ -0,0 +1,130 @@
+package demo
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"text/template"
+)
+
+const (
+	// templateLeftDelim and templateRightDelim enclose the placeholders in
+	// titles, descriptions, step texts and commands. They differ from the
+	// text/template defaults to not conflict with Go templates passed to
+	// tools, like `docker inspect --format '{{.State}}'`.
+	templateLeftDelim  = "${{"
+	templateRightDelim = "}}"
+)
+
+// SetData sets a value which can be referenced by the title, description,
+// step texts and commands of the run via `${{ .Data.key }}`. Values provided
+// via the command line take precedence.
+func (r *Run) SetData(key string, value any) {
+	if r.data == nil {
+		r.data = map[string]any{}
+	}
+
+	r.data[key] = value
+}
+
+// inheritData adds all provided values which have not been set on the run.
+func (r *Run) inheritData(data map[string]any) {
+	for key, value := range data {
+		if _, ok := r.data[key]; !ok {
+			r.SetData(key, value)
+		}
+	}
+}
+
+// expand renders all template placeholders in the provided text.
+func (r *Run) expand(text string, data map[string]any) (string, error) {
+	if !strings.Contains(text, templateLeftDelim) {
+		return text, nil
+	}
+
+	tmpl := template.New("step").Delims(templateLeftDelim, templateRightDelim)
+
+	// Captured variables are not available in dry-run mode.
+	if !r.options.DryRun {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+
+	tmpl, err := tmpl.Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+
+	res := &strings.Builder{}
+	if err := tmpl.Execute(res, data); err != nil {
+		return "", fmt.Errorf("expand template: %w", err)
+	}
+
+	return res.String(), nil
+}
+
+// expandDir renders the template placeholders of a Chdir directory, which
+// get the data of the title and description.
+func (r *Run) expandDir(dir string) (string, error) {
+	return r.expand(dir, r.templateData(0))
+}
+
+// expandAll renders all template placeholders in the provided lines.
+func (r *Run) expandAll(lines []string, data map[string]any) ([]string, error) {
+	if lines == nil {
+		return nil, nil
+	}
+
+	res := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		expanded, err := r.expand(line, data)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, expanded)
+	}
+
+	return res, nil
+}
+
+// expandedTitle returns the title with expanded template placeholders, or
+// the unexpanded title if it cannot be expanded.
+func (r *Run) expandedTitle() string {
+	return r.preview([]string{r.title}, 0)[0]
+}
+
+// templateData returns the data available in template placeholders for the
+// provided step number, which is zero for the title and description.
+func (r *Run) templateData(step int) map[string]any {
+	data := maps.Clone(r.data)
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	maps.Copy(data, r.options.Data)
+
+	return map[string]any{
+		"Data":    data,
+		"Env":     environment(r.env),
+		"Step":    step,
+		"Title":   r.title,
+		"Vars":    r.vars,
+		"WorkDir": r.dir,
+	}
+}
+
+// environment returns the process environment extended by the provided
+// "KEY=VALUE" entries as map.
+func environment(env []string) map[string]string {
+	res := map[string]string{}
+
+	for _, e := range append(os.Environ(), env...) {
+		if key, value, ok := strings.Cut(e, "="); ok {
+			res[key] = value
+		}
+	}
+
+	return res
+}
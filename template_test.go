@@ -0,0 +1,118 @@
+package demo_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Template", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Deploy ${{ .Data.version }}", "to the ${{ .Data.cluster }} cluster")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{Auto: true, Immediate: true}
+	})
+
+	It("should succeed to expand the run data", func() {
+		// Given
+		sut.SetData("version", "v1.0.0")
+		sut.SetData("cluster", "staging")
+		sut.Step(demo.S("Install ${{ .Data.version }}"), demo.S("echo installing-${{ .Data.version }}"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("Deploy v1.0.0\n=============\n"))
+		Expect(out.String()).To(ContainSubstring("to the staging cluster"))
+		Expect(out.String()).To(ContainSubstring("# Install v1.0.0 [1/1]:"))
+		Expect(out.String()).To(ContainSubstring("installing-v1.0.0\n"))
+	})
+
+	It("should prefer the data of the options", func() {
+		// Given
+		sut.SetData("version", "v1.0.0")
+		sut.SetData("cluster", "staging")
+		opts.Data = map[string]any{"cluster": "production"}
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("Deploy v1.0.0"))
+		Expect(out.String()).To(ContainSubstring("to the production cluster"))
+	})
+
+	It("should succeed to expand the built-ins", func() {
+		// Given
+		sut = demo.NewRun("Title")
+		Expect(sut.SetOutput(out)).To(Succeed())
+		sut.SetEnv("TEMPLATE_VAR=from-env")
+		sut.SetWorkDir("/tmp")
+		sut.Step(nil, demo.S("echo first"))
+		sut.Step(nil, demo.S(
+			"echo '${{ .Env.TEMPLATE_VAR }} ${{ .WorkDir }} ${{ .Step }} ${{ .Title }}'",
+		))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("from-env /tmp 2 Title\n"))
+	})
+
+	It("should succeed to expand the directories of Chdir steps", func() {
+		// Given
+		dir := GinkgoT().TempDir()
+		sut = demo.NewRun("Title")
+		Expect(sut.SetOutput(out)).To(Succeed())
+		sut.SetData("dir", dir)
+		sut.Chdir("${{ .Data.dir }}")
+		sut.Step(nil, demo.S("pwd"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring("cd " + dir + "\n"))
+		Expect(out.String()).To(ContainSubstring("\n" + dir + "\n"))
+	})
+
+	It("should fail on missing data", func() {
+		// Given
+		sut.SetData("version", "v1.0.0")
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`map has no entry for key "cluster"`)))
+	})
+
+	It("should fail on an invalid template", func() {
+		// Given
+		sut = demo.NewRun("Title")
+		Expect(sut.SetOutput(out)).To(Succeed())
+		sut.Step(nil, demo.S("echo ${{ .Data.version"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("parse template")))
+	})
+})
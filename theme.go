@@ -0,0 +1,374 @@
+package demo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	// errUnknownTheme is the error returned for theme names which are
+	// neither built-in nor an existing file.
+	errUnknownTheme = errors.New("unknown theme")
+
+	// errUnknownAttribute is the error returned for unsupported style
+	// attributes in theme files.
+	errUnknownAttribute = errors.New("unknown style attribute")
+)
+
+const (
+	// ThemeDefault is the name of the default theme.
+	ThemeDefault = "default"
+
+	// ThemeHighContrast is the name of the high-contrast theme, which avoids
+	// faint text and is well readable on projectors.
+	ThemeHighContrast = "high-contrast"
+
+	// ThemeLight is the name of the theme for terminals with light
+	// background.
+	ThemeLight = "light"
+
+	// ThemeMonochrome is the name of the theme without any colors.
+	ThemeMonochrome = "monochrome"
+)
+
+// Theme defines the styles of the demo output. A nil style keeps the
+// corresponding text unstyled.
+type Theme struct {
+	// Title is used for the title of runs.
+	Title *color.Color
+
+	// Underline is used for the line below the title of runs.
+	Underline *color.Color
+
+	// Description is used for the description of runs and the texts of steps.
+	Description *color.Color
+
+	// StepCounter is used for the step counter behind step texts, like
+	// `[1/3]`.
+	StepCounter *color.Color
+
+	// Prompt is used for the prompt in front of commands.
+	Prompt *color.Color
+
+	// Command is used for highlighting the displayed commands.
+	Command *SyntaxTheme
+
+	// Output is used for the output of commands.
+	Output *color.Color
+
+	// Error is used for messages about failed commands, like retries.
+	Error *color.Color
+
+	// BreakPoint is used for the breakpoint indicator.
+	BreakPoint *color.Color
+}
+
+// DefaultTheme returns the default theme.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Title:       color.New(color.FgCyan),
+		Underline:   color.New(color.FgCyan),
+		Description: color.New(color.FgWhite, color.Faint),
+		StepCounter: color.New(color.FgWhite, color.Faint),
+		Prompt:      color.New(color.FgGreen),
+		Command:     DefaultSyntaxTheme(),
+		Error:       color.New(color.FgRed),
+		BreakPoint:  color.New(color.FgYellow),
+	}
+}
+
+// HighContrastTheme returns a theme with bright and bold colors and without
+// faint text, which is well readable on projectors.
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Title:       color.New(color.FgHiYellow, color.Bold),
+		Underline:   color.New(color.FgHiYellow, color.Bold),
+		Description: color.New(color.FgHiWhite),
+		StepCounter: color.New(color.FgHiCyan, color.Bold),
+		Prompt:      color.New(color.FgHiGreen, color.Bold),
+		Command: &SyntaxTheme{
+			Command:  color.New(color.FgHiGreen, color.Bold),
+			Flag:     color.New(color.FgHiCyan, color.Bold),
+			String:   color.New(color.FgHiYellow),
+			Variable: color.New(color.FgHiMagenta, color.Bold),
+			Operator: color.New(color.FgHiRed, color.Bold),
+			Comment:  color.New(color.FgHiWhite),
+			Text:     color.New(color.FgHiWhite),
+		},
+		Output:     color.New(color.FgHiWhite),
+		Error:      color.New(color.FgHiRed, color.Bold),
+		BreakPoint: color.New(color.FgHiYellow, color.Bold),
+	}
+}
+
+// LightTheme returns a theme for terminals with light background.
+func LightTheme() *Theme {
+	return &Theme{
+		Title:       color.New(color.FgBlue, color.Bold),
+		Underline:   color.New(color.FgBlue),
+		Description: color.New(color.FgHiBlack),
+		StepCounter: color.New(color.FgHiBlack),
+		Prompt:      color.New(color.FgMagenta),
+		Command: &SyntaxTheme{
+			Command:  color.New(color.FgBlue, color.Bold),
+			Flag:     color.New(color.FgCyan),
+			String:   color.New(color.FgRed),
+			Variable: color.New(color.FgMagenta),
+			Operator: color.New(color.FgHiBlack, color.Bold),
+			Comment:  color.New(color.FgHiBlack, color.Italic),
+			Text:     color.New(color.FgBlack),
+		},
+		Error:      color.New(color.FgRed, color.Bold),
+		BreakPoint: color.New(color.FgMagenta, color.Bold),
+	}
+}
+
+// MonochromeTheme returns a theme without colors, which only uses text
+// attributes like bold and underline.
+func MonochromeTheme() *Theme {
+	return &Theme{
+		Title:      color.New(color.Bold),
+		Underline:  color.New(color.Bold),
+		Prompt:     color.New(color.Bold),
+		Command:    MonochromeSyntaxTheme(),
+		Error:      color.New(color.Bold),
+		BreakPoint: color.New(color.ReverseVideo),
+	}
+}
+
+// builtinThemes returns the constructors of all built-in themes by name.
+func builtinThemes() map[string]func() *Theme {
+	return map[string]func() *Theme{
+		ThemeDefault:      DefaultTheme,
+		ThemeHighContrast: HighContrastTheme,
+		ThemeLight:        LightTheme,
+		ThemeMonochrome:   MonochromeTheme,
+	}
+}
+
+// ThemeNames returns the names of all built-in themes.
+func ThemeNames() []string {
+	return slices.Sorted(maps.Keys(builtinThemes()))
+}
+
+// themeFrom returns the built-in theme of the provided name or loads the
+// theme file at the provided path.
+func themeFrom(nameOrPath string) (*Theme, error) {
+	if nameOrPath == "" {
+		return DefaultTheme(), nil
+	}
+
+	if theme, ok := builtinThemes()[nameOrPath]; ok {
+		return theme(), nil
+	}
+
+	if _, err := os.Stat(nameOrPath); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(
+			"%w %q, use one of %s or a theme file",
+			errUnknownTheme, nameOrPath, strings.Join(ThemeNames(), ", "),
+		)
+	}
+
+	return LoadTheme(nameOrPath)
+}
+
+// themeDefinition is the structure of a YAML theme file.
+type themeDefinition struct {
+	Title       *styleDefinition       `yaml:"title"`
+	Underline   *styleDefinition       `yaml:"underline"`
+	Description *styleDefinition       `yaml:"description"`
+	StepCounter *styleDefinition       `yaml:"stepCounter"`
+	Prompt      *styleDefinition       `yaml:"prompt"`
+	Command     *syntaxThemeDefinition `yaml:"command"`
+	Output      *styleDefinition       `yaml:"output"`
+	Error       *styleDefinition       `yaml:"error"`
+	BreakPoint  *styleDefinition       `yaml:"breakPoint"`
+}
+
+type syntaxThemeDefinition struct {
+	Command  *styleDefinition `yaml:"command"`
+	Flag     *styleDefinition `yaml:"flag"`
+	String   *styleDefinition `yaml:"string"`
+	Variable *styleDefinition `yaml:"variable"`
+	Operator *styleDefinition `yaml:"operator"`
+	Comment  *styleDefinition `yaml:"comment"`
+	Text     *styleDefinition `yaml:"text"`
+}
+
+// styleDefinition is a style of a theme file, which consists of a single or a
+// list of attributes, like `[cyan, bold]`. An empty list disables the style.
+type styleDefinition struct {
+	color *color.Color
+}
+
+// LoadTheme loads the YAML theme file at the provided path. Styles which are
+// not part of the file are taken from the default theme. A theme file looks
+// like this:
+//
+//	title: [hiYellow, bold]
+//	underline: hiYellow
+//	description: white
+//	stepCounter: [cyan, bold]
+//	prompt: green
+//	command:
+//	  command: [green, bold]
+//	  flag: cyan
+//	  string: yellow
+//	  variable: magenta
+//	  operator: red
+//	  comment: faint
+//	  text: []
+//	output: []
+//	error: [red, bold]
+//	breakPoint: [yellow, reverse]
+//
+// Supported attributes are the colors black, red, green, yellow, blue,
+// magenta, cyan and white, their bright variants like hiRed, background
+// colors like bgRed or bgHiRed as well as bold, faint, italic, underline,
+// blink and reverse.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read theme: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var def themeDefinition
+	if err := decoder.Decode(&def); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: parse theme: %w", path, err)
+	}
+
+	return def.build(), nil
+}
+
+func (d *themeDefinition) build() *Theme {
+	theme := DefaultTheme()
+
+	d.Title.apply(&theme.Title)
+	d.Underline.apply(&theme.Underline)
+	d.Description.apply(&theme.Description)
+	d.StepCounter.apply(&theme.StepCounter)
+	d.Prompt.apply(&theme.Prompt)
+	d.Output.apply(&theme.Output)
+	d.Error.apply(&theme.Error)
+	d.BreakPoint.apply(&theme.BreakPoint)
+
+	if c := d.Command; c != nil {
+		c.Command.apply(&theme.Command.Command)
+		c.Flag.apply(&theme.Command.Flag)
+		c.String.apply(&theme.Command.String)
+		c.Variable.apply(&theme.Command.Variable)
+		c.Operator.apply(&theme.Command.Operator)
+		c.Comment.apply(&theme.Command.Comment)
+		c.Text.apply(&theme.Command.Text)
+	}
+
+	return theme
+}
+
+// apply replaces the provided style if it has been defined.
+func (s *styleDefinition) apply(style **color.Color) {
+	if s != nil {
+		*style = s.color
+	}
+}
+
+// UnmarshalYAML decodes a single attribute or a list of attributes.
+func (s *styleDefinition) UnmarshalYAML(node *yaml.Node) error {
+	var names lines
+	if err := node.Decode(&names); err != nil {
+		//nolint:wrapcheck // errors already contain the line number
+		return err
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	attributes := make([]color.Attribute, 0, len(names))
+
+	for _, name := range names {
+		attribute, ok := styleAttributes()[name]
+		if !ok {
+			return fmt.Errorf("line %d: %w %q", node.Line, errUnknownAttribute, name)
+		}
+
+		attributes = append(attributes, attribute)
+	}
+
+	s.color = color.New(attributes...)
+
+	return nil
+}
+
+// styleAttributes returns the supported style attributes of theme files.
+func styleAttributes() map[string]color.Attribute {
+	attributes := map[string]color.Attribute{
+		"bold":      color.Bold,
+		"faint":     color.Faint,
+		"italic":    color.Italic,
+		"underline": color.Underline,
+		"blink":     color.BlinkSlow,
+		"reverse":   color.ReverseVideo,
+	}
+
+	colors := []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+	for i, name := range colors {
+		title := strings.ToUpper(name[:1]) + name[1:]
+		offset := color.Attribute(i)
+
+		attributes[name] = color.FgBlack + offset
+		attributes["hi"+title] = color.FgHiBlack + offset
+		attributes["bg"+title] = color.BgBlack + offset
+		attributes["bgHi"+title] = color.BgHiBlack + offset
+	}
+
+	return attributes
+}
+
+// style formats the text in the provided style, unless colors are disabled.
+func (r *Run) style(style *color.Color, format string, a ...any) string {
+	return r.options.style(style, format, a...)
+}
+
+func (o *Options) style(style *color.Color, format string, a ...any) string {
+	if style == nil || o.NoColor {
+		return fmt.Sprintf(format, a...)
+	}
+
+	return style.Sprintf(format, a...)
+}
+
+// theme returns the theme of the run.
+func (r *Run) theme() *Theme {
+	if r.options.Theme == nil {
+		return DefaultTheme()
+	}
+
+	return r.options.Theme
+}
+
+// styleOutput applies the output style of the theme to everything written
+// to the output of the run while executing the provided function.
+func (r *Run) styleOutput(enabled bool, fn func() error) error {
+	style := r.theme().Output
+	if !enabled || style == nil || r.options.NoColor {
+		return fn()
+	}
+
+	style.SetWriter(r.out)
+	defer style.UnsetWriter(r.out)
+
+	return fn()
+}
@@ -0,0 +1,157 @@
+package demo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+func writeTheme(content string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "theme.yaml")
+	Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+	return path
+}
+
+var _ = Describe("Theme", func() {
+	var (
+		sut  *demo.Run
+		out  *strings.Builder
+		opts demo.Options
+	)
+
+	colored := func(attribute color.Attribute) *color.Color {
+		c := color.New(attribute)
+		c.EnableColor()
+
+		return c
+	}
+
+	// style returns the text in the provided SGR color code.
+	style := func(code, text string) string {
+		return "\x1b[" + code + "m" + text + "\x1b[0m"
+	}
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title", "Description")
+		out = &strings.Builder{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		opts = demo.Options{
+			Auto:      true,
+			Immediate: true,
+			Theme: &demo.Theme{
+				Title:       colored(color.FgRed),
+				Underline:   colored(color.FgGreen),
+				Description: colored(color.FgYellow),
+				StepCounter: colored(color.FgBlue),
+				Prompt:      colored(color.FgMagenta),
+				Output:      colored(color.FgCyan),
+				Error:       colored(color.FgWhite),
+			},
+		}
+	})
+
+	It("should style the output", func() {
+		// Given
+		sut.Step(demo.S("Text"), demo.S("echo hello"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(HavePrefix(
+			style("31", "Title\n") + strings.Repeat(style("32", "="), len("Title")) + "\n" +
+				style("33", "Description\n") + "\n",
+		))
+		Expect(out.String()).To(ContainSubstring(
+			style("33", "# Text ") + style("34", "[1/1]") + style("33", ":\n"),
+		))
+		Expect(out.String()).To(ContainSubstring(style("35", ">") + " echo hello"))
+		Expect(out.String()).To(ContainSubstring("\x1b[36mhello\n\x1b[0m"))
+	})
+
+	It("should style retry messages", func() {
+		// Given
+		sut.Step(nil, demo.S("false"), demo.WithRetries(1, time.Millisecond))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).To(HaveOccurred())
+		Expect(out.String()).To(ContainSubstring(style("37", "# exit status 1, retrying in 1ms\n")))
+	})
+
+	It("should not style the output without colors", func() {
+		// Given
+		opts.NoColor = true
+		sut.Step(demo.S("Text"), demo.S("echo hello"))
+
+		// When
+		err := sut.RunWithOptions(&opts)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).NotTo(ContainSubstring("\x1b["))
+		Expect(out.String()).To(ContainSubstring("# Text [1/1]:\n\n> echo hello\nhello\n"))
+	})
+
+	It("should provide the built-in themes by name", func() {
+		Expect(demo.ThemeNames()).To(Equal([]string{
+			demo.ThemeDefault, demo.ThemeHighContrast, demo.ThemeLight, demo.ThemeMonochrome,
+		}))
+	})
+
+	It("should load a theme file", func() {
+		// Given
+		path := writeTheme(`
+title: [hiYellow, bold]
+description: []
+command:
+  flag: bgHiBlue
+`)
+
+		// When
+		theme, err := demo.LoadTheme(path)
+
+		// Then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(theme.Title.Equals(color.New(color.FgHiYellow, color.Bold))).To(BeTrue())
+		Expect(theme.Description).To(BeNil())
+		Expect(theme.Command.Flag.Equals(color.New(color.BgHiBlue))).To(BeTrue())
+
+		defaults := demo.DefaultTheme()
+		Expect(theme.Underline.Equals(defaults.Underline)).To(BeTrue())
+		Expect(theme.Command.Command.Equals(defaults.Command.Command)).To(BeTrue())
+	})
+
+	It("should fail to load a theme file with an unknown attribute", func() {
+		// Given
+		path := writeTheme("title: [purple]")
+
+		// When
+		_, err := demo.LoadTheme(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring(`line 1: unknown style attribute "purple"`)))
+	})
+
+	It("should fail to load a theme file with an unknown style", func() {
+		// Given
+		path := writeTheme("heading: red")
+
+		// When
+		_, err := demo.LoadTheme(path)
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("field heading not found")))
+	})
+})
@@ -3,18 +3,23 @@ package demo
 import (
 	"context"
 	"fmt"
-	"os/exec"
 )
 
 // EnsureWithContext executes the provided commands in order with the given context.
 // This utility function can be used during setup or cleanup.
 func EnsureWithContext(ctx context.Context, commands ...string) error {
-	for _, c := range commands {
-		cmd := exec.CommandContext(ctx, "bash", "-c", c)
-		cmd.Stderr = nil
-		cmd.Stdout = nil
+	return EnsureWithExecutor(ctx, &LocalExecutor{}, commands...)
+}
 
-		if err := cmd.Run(); err != nil {
+// EnsureWithExecutor executes the provided commands in order with the given
+// context and executor. This utility function can be used during setup or cleanup.
+func EnsureWithExecutor(ctx context.Context, executor Executor, commands ...string) error {
+	if executor == nil {
+		return errExecutorNil
+	}
+
+	for _, c := range commands {
+		if err := executor.Execute(ctx, &Execution{Command: c}); err != nil {
 			return fmt.Errorf("run command: %w", err)
 		}
 	}
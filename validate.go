@@ -0,0 +1,417 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	// errValidationFailed is the error returned if at least one run has
+	// problems in validation mode.
+	errValidationFailed = errors.New("validation failed")
+
+	// errSyntax is the problem reported for commands the shell cannot parse.
+	errSyntax = errors.New("syntax error")
+
+	// errExecutableNotFound is the problem reported for commands which refer
+	// to executables not available in PATH.
+	errExecutableNotFound = errors.New("executable not found")
+
+	// errDirNotFound is the problem reported for working directories which
+	// do not exist.
+	errDirNotFound = errors.New("directory not found")
+)
+
+// StepValidation is the outcome of validating a single step via
+// Run.Validate.
+type StepValidation struct {
+	// Index is the step number as shown during the demo, or zero for the
+	// working directory of the run and Chdir steps.
+	Index int
+
+	// Command is the joined command of the step, like `cd /tmp` for working
+	// directories.
+	Command string
+
+	// Problems contains all issues found for the step.
+	Problems []error
+}
+
+// ValidationResult is the outcome of validating a run via Run.Validate.
+type ValidationResult struct {
+	// Title is the title of the run.
+	Title string
+
+	// Steps contains the validation of all command steps and working
+	// directories.
+	Steps []StepValidation
+}
+
+// Valid returns true if the step has no problems.
+func (s *StepValidation) Valid() bool {
+	return len(s.Problems) == 0
+}
+
+// Valid returns true if no step of the run has problems.
+func (r *ValidationResult) Valid() bool {
+	for i := range r.Steps {
+		if !r.Steps[i].Valid() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate checks all steps of the run without executing them, based on the
+// provided options. The template placeholders of every command need to be
+// expandable, where captured variables get replaced by their name. The
+// command gets parsed by the syntax checker of the shell (`bash -n`), the
+// executables it refers to need to be available in PATH or be shell builtins
+// and the directories of SetWorkDir and Chdir need to exist. Functions and
+// aliases defined by the command, or by previous steps in session mode, are
+// known as well. Executables and directories are not checked if the run uses
+// an executor other than the LocalExecutor, because they refer to another
+// machine then.
+func (r *Run) Validate(opts *Options) *ValidationResult {
+	validateOpts := *opts
+
+	if validateOpts.Context == nil {
+		validateOpts.Context = context.Background()
+	}
+
+	if validateOpts.Shell == "" {
+		validateOpts.Shell = defaultShell
+	}
+
+	r.options = validateOpts
+	r.vars = map[string]string{}
+
+	v := &validator{
+		ctx:   validateOpts.Context,
+		shell: validateOpts.Shell,
+		path:  environment(r.env)["PATH"],
+		local: r.localExecution(),
+
+		session: r.useSession,
+		defined: map[string]bool{},
+	}
+
+	result := &ValidationResult{Title: r.expandedTitle()}
+	dir := r.dir
+
+	if dir != "" {
+		result.Steps = append(result.Steps, v.validateDir(dir))
+	}
+
+	index := 0
+
+	for _, s := range r.steps {
+		if s.dir != "" {
+			expanded, err := r.expandDir(s.dir)
+			if err != nil {
+				result.Steps = append(result.Steps, StepValidation{Command: "cd " + s.dir, Problems: []error{err}})
+
+				continue
+			}
+
+			dir = expanded
+			result.Steps = append(result.Steps, v.validateDir(dir))
+
+			continue
+		}
+
+		index++
+
+		if len(s.command) == 0 {
+			continue
+		}
+
+		command, err := r.expandAll(s.command, r.templateData(index))
+		if err != nil {
+			result.Steps = append(result.Steps, StepValidation{
+				Index: index, Command: s.commandLine(), Problems: []error{err},
+			})
+		} else {
+			result.Steps = append(result.Steps, v.validateCommand(index, strings.Join(command, " "), dir))
+		}
+
+		if s.capture != nil {
+			r.vars[s.capture.name] = s.capture.name
+		}
+	}
+
+	return result
+}
+
+// validator checks commands and directories of a run.
+type validator struct {
+	//nolint:containedctx // only used for the syntax checks
+	ctx   context.Context
+	shell string
+	path  string
+
+	// local is true if executables and directories are checked.
+	local bool
+
+	// session is true if the functions and aliases defined by a step are
+	// known to all subsequent steps.
+	session bool
+	defined map[string]bool
+}
+
+func (v *validator) validateDir(dir string) StepValidation {
+	res := StepValidation{Command: "cd " + dir}
+
+	if !v.local {
+		return res
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		res.Problems = append(res.Problems, fmt.Errorf("%w: %s", errDirNotFound, dir))
+	}
+
+	return res
+}
+
+func (v *validator) validateCommand(index int, command, dir string) StepValidation {
+	res := StepValidation{Index: index, Command: command}
+
+	//nolint:gosec // the command is only parsed, not executed
+	out, err := exec.CommandContext(v.ctx, v.shell, "-n", "-c", command).CombinedOutput()
+	if err != nil {
+		message := strings.Join(strings.Fields(strings.ReplaceAll(string(out), "\n", "; ")), " ")
+		if message == "" {
+			message = err.Error()
+		}
+
+		res.Problems = append(res.Problems, fmt.Errorf("%w: %s", errSyntax, message))
+
+		// The executables of an unparsable command are meaningless.
+		return res
+	}
+
+	if !v.local {
+		return res
+	}
+
+	defined := definitions(command)
+
+	for _, executable := range executables(command) {
+		if v.defined[executable] || slices.Contains(defined, executable) {
+			continue
+		}
+
+		if !v.exists(executable, dir) {
+			res.Problems = append(res.Problems, fmt.Errorf("%w: %s", errExecutableNotFound, executable))
+		}
+	}
+
+	if v.session {
+		for _, name := range defined {
+			v.defined[name] = true
+		}
+	}
+
+	return res
+}
+
+// exists returns true if the executable is a path to an executable file, in
+// PATH or known to the shell, like builtins and keywords.
+func (v *validator) exists(executable, dir string) bool {
+	if strings.ContainsRune(executable, '/') {
+		if !filepath.IsAbs(executable) && dir != "" {
+			executable = filepath.Join(dir, executable)
+		}
+
+		return isExecutable(executable)
+	}
+
+	for _, p := range filepath.SplitList(v.path) {
+		if p != "" && isExecutable(filepath.Join(p, executable)) {
+			return true
+		}
+	}
+
+	//nolint:gosec // the executable is passed as argument, not interpreted
+	cmd := exec.CommandContext(v.ctx, v.shell, "-c", `command -v -- "$1"`, "validate", executable)
+
+	return cmd.Run() == nil
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
+// executables returns the names of the executables a command line refers to,
+// based on the tokens the syntax highlighting uses. Names containing
+// expansions cannot be checked and are omitted.
+func executables(command string) []string {
+	var res []string
+
+	tokens := tokenize(command)
+	for i, t := range tokens {
+		if t.kind != tokenCommand {
+			continue
+		}
+
+		// Words continuing with a string or variable, like `foo"bar"`, are
+		// only known at execution time.
+		if i+1 < len(tokens) && tokens[i+1].kind != tokenText && tokens[i+1].kind != tokenOperator &&
+			tokens[i+1].kind != tokenComment {
+			continue
+		}
+
+		// Function definitions like `greet() { ...; }` do not refer to an
+		// executable.
+		if next := nextToken(tokens, i); next != nil && next.kind == tokenOperator && next.text == "(" {
+			continue
+		}
+
+		if strings.ContainsAny(t.text, "*?[{~\\") {
+			continue
+		}
+
+		res = append(res, t.text)
+	}
+
+	return res
+}
+
+// definitions returns the names of the functions and aliases a command line
+// defines, like `greet() { ...; }`, `function greet { ...; }` and
+// `alias ll='ls -l'`.
+func definitions(command string) []string {
+	var res []string
+
+	tokens := tokenize(command)
+	for i, t := range tokens {
+		if t.kind != tokenCommand {
+			continue
+		}
+
+		next := nextToken(tokens, i)
+
+		switch {
+		case next == nil:
+		case next.kind == tokenOperator && next.text == "(":
+			res = append(res, t.text)
+		case t.text == "function" && next.kind == tokenText:
+			res = append(res, next.text)
+		case t.text == "alias":
+			res = append(res, aliases(tokens[i+1:])...)
+		}
+	}
+
+	return res
+}
+
+// aliases returns the names of the alias arguments until the end of the
+// simple command.
+func aliases(tokens []token) []string {
+	var res []string
+
+	for _, t := range tokens {
+		if t.kind == tokenOperator || t.kind == tokenComment {
+			break
+		}
+
+		if name, _, ok := strings.Cut(t.text, "="); ok && t.kind == tokenText && name != "" {
+			res = append(res, name)
+		}
+	}
+
+	return res
+}
+
+// nextToken returns the token following the one at index i, skipping
+// whitespace, or nil if there is none.
+func nextToken(tokens []token, i int) *token {
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].kind != tokenText || strings.TrimSpace(tokens[j].text) != "" {
+			return &tokens[j]
+		}
+	}
+
+	return nil
+}
+
+// validate checks all runs without executing them and prints a report for
+// every step.
+func (d *Demo) validate(ctx context.Context, cmd *cli.Command) error {
+	runs, err := d.allRuns(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	invalid := 0
+
+	for _, r := range runs {
+		result := r.Validate(&opts)
+		if !result.Valid() {
+			invalid++
+		}
+
+		if err := printValidationResult(cmd.Root().Writer, result); err != nil {
+			return err
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: %d of %d runs have problems", errValidationFailed, invalid, len(runs))
+	}
+
+	return nil
+}
+
+func printValidationResult(w io.Writer, result *ValidationResult) error {
+	status := "VALID"
+	if !result.Valid() {
+		status = "INVALID"
+	}
+
+	if err := write(w, fmt.Sprintf("%s %s\n", status, result.Title)); err != nil {
+		return err
+	}
+
+	for i := range result.Steps {
+		s := &result.Steps[i]
+
+		step := "-"
+		if s.Index > 0 {
+			step = fmt.Sprint(s.Index)
+		}
+
+		if s.Valid() {
+			if err := write(w, fmt.Sprintf("  [%s] ok: %s\n", step, s.Command)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, problem := range s.Problems {
+			if err := write(w, fmt.Sprintf("  [%s] %v: %s\n", step, problem, s.Command)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
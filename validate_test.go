@@ -0,0 +1,203 @@
+package demo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Validate", func() {
+	var (
+		sut  *demo.Run
+		opts demo.Options
+	)
+
+	problems := func(result *demo.ValidationResult) []string {
+		var res []string
+
+		for i := range result.Steps {
+			for _, problem := range result.Steps[i].Problems {
+				res = append(res, problem.Error())
+			}
+		}
+
+		return res
+	}
+
+	BeforeEach(func() {
+		sut = demo.NewRun("Title")
+		opts = demo.Options{}
+	})
+
+	It("should succeed to validate valid steps", func() {
+		// Given
+		sut.Step(demo.S("Text"), demo.S("FOO=1 echo hello | grep -q h && cd /tmp"))
+		sut.Step(nil, demo.S("if true; then printf '%s' \"$HOME\"; fi"))
+		sut.Step(demo.S("Only text"), nil)
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeTrue())
+		Expect(result.Title).To(Equal("Title"))
+		Expect(result.Steps).To(HaveLen(2))
+		Expect(result.Steps[1].Index).To(Equal(2))
+	})
+
+	It("should not execute the commands", func() {
+		// Given
+		file := filepath.Join(GinkgoT().TempDir(), "executed")
+		sut.Step(nil, demo.S("touch "+file))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeTrue())
+		Expect(file).NotTo(BeAnExistingFile())
+	})
+
+	It("should report syntax errors", func() {
+		// Given
+		sut.Step(nil, demo.S("if true; then echo"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeFalse())
+		Expect(problems(result)).To(ConsistOf(HavePrefix("syntax error: ")))
+	})
+
+	It("should report missing executables", func() {
+		// Given
+		sut.Step(nil, demo.S("echo hello | not-existing-executable && ./not-existing.sh"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(problems(result)).To(Equal([]string{
+			"executable not found: not-existing-executable",
+			"executable not found: ./not-existing.sh",
+		}))
+	})
+
+	It("should not report defined functions and aliases", func() {
+		// Given
+		sut.Step(nil, demo.S("greet() { echo hi; }; greet"))
+		sut.Step(nil, demo.S("function hello { echo hello; } && hello"))
+		sut.Step(nil, demo.S("alias ll='ls -l' la=ls; ll"))
+		sut.Step(nil, demo.S("greet"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(problems(result)).To(Equal([]string{"executable not found: greet"}))
+	})
+
+	It("should know the functions and aliases of previous steps in session mode", func() {
+		// Given
+		sut.SetSession(true)
+		sut.Step(nil, demo.S("greet() { echo hi; }"))
+		sut.Step(nil, demo.S("alias ll='ls -l'"))
+		sut.Step(nil, demo.S("greet && ll"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeTrue())
+	})
+
+	It("should find executables relative to the working directory", func() {
+		// Given
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "script.sh"), []byte("#!/bin/sh\n"), 0o700)).To(Succeed())
+
+		sut.Chdir(dir)
+		sut.Step(nil, demo.S("./script.sh"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeTrue())
+		Expect(result.Steps[0].Command).To(Equal("cd " + dir))
+	})
+
+	It("should report missing directories", func() {
+		// Given
+		sut.SetWorkDir("/not/existing/workdir")
+		sut.Chdir("/not/existing/chdir")
+		sut.Step(nil, demo.S("echo hello"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(problems(result)).To(Equal([]string{
+			"directory not found: /not/existing/workdir",
+			"directory not found: /not/existing/chdir",
+		}))
+	})
+
+	It("should replace captured variables and report missing template data", func() {
+		// Given
+		sut.Step(nil, demo.S("echo id"), demo.CaptureAs("id"))
+		sut.Step(nil, demo.S("echo ${{ .Vars.id }}"))
+		sut.Step(nil, demo.S("echo ${{ .Data.missing }}"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Steps[1].Command).To(Equal("echo id"))
+		Expect(result.Steps[1].Valid()).To(BeTrue())
+		Expect(problems(result)).To(ConsistOf(ContainSubstring(`map has no entry for key "missing"`)))
+	})
+
+	It("should not check executables and directories of other executors", func() {
+		// Given
+		Expect(sut.SetExecutor(&demo.ContainerExecutor{Container: "demo"})).To(Succeed())
+		sut.SetWorkDir("/only/in/container")
+		sut.Step(nil, demo.S("only-in-container"))
+
+		// When
+		result := sut.Validate(&opts)
+
+		// Then
+		Expect(result.Valid()).To(BeTrue())
+	})
+
+	It("should print a report and fail for invalid demos", func() {
+		// Given
+		out := &strings.Builder{}
+		sut.Step(nil, demo.S("echo valid"))
+		sut.Step(nil, demo.S("not-existing-executable"))
+
+		var err error
+
+		// When
+		withArgs([]string{appName, "--validate"}, func() {
+			d := demo.New()
+			d.Writer = out
+			d.Add(sut, "run", "invalid run")
+			err = d.RunE()
+		})
+
+		// Then
+		Expect(err).To(MatchError(ContainSubstring("validation failed: 1 of 1 runs have problems")))
+		Expect(out.String()).To(Equal(
+			"INVALID Title\n" +
+				"  [1] ok: echo valid\n" +
+				"  [2] executable not found: not-existing-executable: not-existing-executable\n",
+		))
+	})
+})
@@ -0,0 +1,471 @@
+package demo
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// errVerificationFailed is the error returned if at least one run failed
+// in verification mode.
+var errVerificationFailed = errors.New("verification failed")
+
+const (
+	// reportFileMode is the file mode used for the written reports.
+	reportFileMode = 0o644
+
+	// statusPassed is the report status of a successful step.
+	statusPassed = "passed"
+
+	// statusFailed is the report status of a failed step.
+	statusFailed = "failed"
+
+	// statusSkipped is the report status of a step not executed.
+	statusSkipped = "skipped"
+)
+
+// StepResult is the outcome of a single command step executed via Run.Verify.
+type StepResult struct {
+	// Index is the step number as shown during the demo.
+	Index int
+
+	// Text is the joined description of the step.
+	Text string
+
+	// Command is the joined command of the step.
+	Command string
+
+	// CanFail indicates that a failure of the step does not fail the run.
+	CanFail bool
+
+	// Skipped indicates that the step was not executed, because a previous
+	// step failed.
+	Skipped bool
+
+	// Duration is the execution time of the command.
+	Duration time.Duration
+
+	// Output is the combined stdout and stderr of the command.
+	Output string
+
+	// Err is the error of the command, nil if it succeeded.
+	Err error
+}
+
+// RunResult is the outcome of a run executed via Run.Verify.
+type RunResult struct {
+	// Title is the title of the run.
+	Title string
+
+	// Steps contains the results of all command steps.
+	Steps []StepResult
+
+	// Duration is the execution time of the whole run.
+	Duration time.Duration
+
+	// Err is the error of the run, nil if it succeeded.
+	Err error
+}
+
+// Passed returns true if the step succeeded.
+func (s *StepResult) Passed() bool {
+	return !s.Skipped && s.Err == nil
+}
+
+// Passed returns true if the whole run succeeded.
+func (r *RunResult) Passed() bool {
+	return r.Err == nil
+}
+
+// Verify executes all steps of the run headlessly in Auto and Immediate mode
+// without any timeouts, based on the provided options. The output is not
+// written to the run output, but captured per step in the returned result.
+func (r *Run) Verify(opts *Options) *RunResult {
+	verifyOpts := *opts
+	verifyOpts.Auto = true
+	verifyOpts.Immediate = true
+	verifyOpts.AutoTimeout = 0
+	verifyOpts.BreakPoint = false
+	verifyOpts.DryRun = false
+	verifyOpts.ContinueOnError = false
+	verifyOpts.SkipSteps = 0
+
+	out := r.out
+	r.out = io.Discard
+	r.report = &RunResult{Title: r.title}
+
+	defer func() {
+		r.out = out
+		r.report = nil
+	}()
+
+	start := time.Now()
+	err := r.RunWithOptions(&verifyOpts)
+
+	result := r.report
+	result.Title = r.heading
+	result.Duration = time.Since(start)
+	result.Err = err
+	result.addSkipped(r.steps)
+
+	return result
+}
+
+// record adds the result of an executed step if the run is verified.
+func (r *Run) record(s *step, index int, duration time.Duration, output string, err error) {
+	if r.report == nil {
+		return
+	}
+
+	r.report.Steps = append(r.report.Steps, StepResult{
+		Index:    index,
+		Text:     strings.Join(s.text, " "),
+		Command:  s.commandLine(),
+		CanFail:  s.canFail,
+		Duration: duration,
+		Output:   output,
+		Err:      err,
+	})
+}
+
+// addSkipped adds all command steps which have not been executed.
+func (r *RunResult) addSkipped(steps []step) {
+	last := 0
+	if len(r.Steps) > 0 {
+		last = r.Steps[len(r.Steps)-1].Index
+	}
+
+	index := 0
+
+	for _, s := range steps {
+		if s.dir != "" {
+			continue
+		}
+
+		index++
+
+		if index <= last || len(s.command) == 0 {
+			continue
+		}
+
+		r.Steps = append(r.Steps, StepResult{
+			Index:   index,
+			Text:    strings.Join(s.text, " "),
+			Command: s.commandLine(),
+			CanFail: s.canFail,
+			Skipped: true,
+		})
+	}
+}
+
+// verify runs all registered demos in verification mode and writes the
+// requested reports.
+func (d *Demo) verify(ctx context.Context, cmd *cli.Command) error {
+	runs, err := d.allRuns(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts, err := optionsFrom(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*RunResult, 0, len(runs))
+	failed := 0
+
+	for _, r := range runs {
+		if err := d.setup(ctx, cmd); err != nil {
+			return err
+		}
+
+		result := r.Verify(&opts)
+		results = append(results, result)
+
+		if !result.Passed() {
+			failed++
+		}
+
+		if err := printRunResult(cmd.Root().Writer, result); err != nil {
+			return err
+		}
+
+		if err := d.cleanup(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	if err := writeReports(cmd, results); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d runs failed", errVerificationFailed, failed, len(results))
+	}
+
+	return nil
+}
+
+// allRuns returns all registered runs and the ones of the definition file,
+// which inherit the data and prompt of the demo.
+func (d *Demo) allRuns(cmd *cli.Command) ([]*Run, error) {
+	runs := make([]*Run, 0, len(d.runs))
+	for _, x := range d.runs {
+		runs = append(runs, x.run)
+	}
+
+	loaded, err := loadFileRuns(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	runs = append(runs, loaded...)
+	d.inherit(runs)
+
+	return runs, nil
+}
+
+func printRunResult(w io.Writer, result *RunResult) error {
+	status := "PASS"
+	if !result.Passed() {
+		status = "FAIL"
+	}
+
+	if err := write(w, fmt.Sprintf(
+		"%s %s (%d steps, %s)\n",
+		status, result.Title, len(result.Steps), result.Duration.Round(time.Millisecond),
+	)); err != nil {
+		return err
+	}
+
+	for i := range result.Steps {
+		s := &result.Steps[i]
+		if s.Err == nil || s.CanFail {
+			continue
+		}
+
+		if err := write(w, fmt.Sprintf("  step %d failed: %s: %v\n", s.Index, s.Command, s.Err)); err != nil {
+			return err
+		}
+	}
+
+	if result.Err != nil && !hasFailedStep(result) {
+		return write(w, fmt.Sprintf("  %v\n", result.Err))
+	}
+
+	return nil
+}
+
+func hasFailedStep(result *RunResult) bool {
+	for i := range result.Steps {
+		if result.Steps[i].Err != nil && !result.Steps[i].CanFail {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeReports(cmd *cli.Command, results []*RunResult) error {
+	if path := cmd.String(FlagJUnitReport); path != "" {
+		data, err := xml.MarshalIndent(newJUnitReport(results), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal junit report: %w", err)
+		}
+
+		if err := os.WriteFile(path, append([]byte(xml.Header), data...), reportFileMode); err != nil {
+			return fmt.Errorf("write junit report: %w", err)
+		}
+	}
+
+	if path := cmd.String(FlagJSONReport); path != "" {
+		data, err := json.MarshalIndent(newJSONReport(results), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal json report: %w", err)
+		}
+
+		if err := os.WriteFile(path, data, reportFileMode); err != nil {
+			return fmt.Errorf("write json report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func newJUnitReport(results []*RunResult) *junitTestSuites {
+	report := &junitTestSuites{Name: "demo"}
+
+	var total time.Duration
+
+	for _, result := range results {
+		suite := junitTestSuite{Name: result.Title, Time: seconds(result.Duration)}
+
+		for i := range result.Steps {
+			s := &result.Steps[i]
+			testCase := junitTestCase{
+				Name:      fmt.Sprintf("[%d] %s", s.Index, stepName(s)),
+				ClassName: result.Title,
+				Time:      seconds(s.Duration),
+				SystemOut: s.Output,
+			}
+
+			switch {
+			case s.Skipped:
+				testCase.Skipped = &junitMessage{Message: "previous step failed"}
+				suite.Skipped++
+			case s.Err != nil && !s.CanFail:
+				testCase.Failure = &junitMessage{Message: s.Err.Error(), Content: s.Output}
+				suite.Failures++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		if result.Err != nil && !hasFailedStep(result) {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      "run",
+				ClassName: result.Title,
+				Time:      seconds(result.Duration),
+				Failure:   &junitMessage{Message: result.Err.Error()},
+			})
+			suite.Failures++
+		}
+
+		suite.Tests = len(suite.TestCases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+		total += result.Duration
+	}
+
+	report.Time = seconds(total)
+
+	return report
+}
+
+type jsonReport struct {
+	Passed bool            `json:"passed"`
+	Runs   []jsonRunReport `json:"runs"`
+}
+
+type jsonRunReport struct {
+	Title    string           `json:"title"`
+	Passed   bool             `json:"passed"`
+	Duration float64          `json:"duration"`
+	Error    string           `json:"error,omitempty"`
+	Steps    []jsonStepReport `json:"steps"`
+}
+
+type jsonStepReport struct {
+	Index    int     `json:"index"`
+	Text     string  `json:"text,omitempty"`
+	Command  string  `json:"command"`
+	CanFail  bool    `json:"canFail"`
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration"`
+	Output   string  `json:"output"`
+	Error    string  `json:"error,omitempty"`
+}
+
+func newJSONReport(results []*RunResult) *jsonReport {
+	report := &jsonReport{Passed: true, Runs: make([]jsonRunReport, 0, len(results))}
+
+	for _, result := range results {
+		run := jsonRunReport{
+			Title:    result.Title,
+			Passed:   result.Passed(),
+			Duration: result.Duration.Seconds(),
+			Error:    errorString(result.Err),
+			Steps:    make([]jsonStepReport, 0, len(result.Steps)),
+		}
+
+		for i := range result.Steps {
+			s := &result.Steps[i]
+
+			status := statusPassed
+			if s.Skipped {
+				status = statusSkipped
+			} else if s.Err != nil {
+				status = statusFailed
+			}
+
+			run.Steps = append(run.Steps, jsonStepReport{
+				Index:    s.Index,
+				Text:     s.Text,
+				Command:  s.Command,
+				CanFail:  s.CanFail,
+				Status:   status,
+				Duration: s.Duration.Seconds(),
+				Output:   s.Output,
+				Error:    errorString(s.Err),
+			})
+		}
+
+		report.Passed = report.Passed && run.Passed
+		report.Runs = append(report.Runs, run)
+	}
+
+	return report
+}
+
+func stepName(s *StepResult) string {
+	if s.Text != "" {
+		return s.Text
+	}
+
+	return s.Command
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,158 @@
+package demo_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/saschagrunert/demo"
+)
+
+var _ = Describe("Verify", func() {
+	It("should succeed to verify a run", func() {
+		// Given
+		sut := demo.NewRun("Verified")
+		sut.Step(demo.S("First"), demo.S("echo first"))
+		sut.Step(demo.S("Description only"), nil)
+		sut.StepCanFail(demo.S("Allowed to fail"), demo.S("exit 1"))
+
+		// When
+		result := sut.Verify(&demo.Options{})
+
+		// Then
+		Expect(result.Passed()).To(BeTrue())
+		Expect(result.Title).To(Equal("Verified"))
+		Expect(result.Steps).To(HaveLen(2))
+		Expect(result.Steps[0].Index).To(Equal(1))
+		Expect(result.Steps[0].Passed()).To(BeTrue())
+		Expect(result.Steps[0].Output).To(Equal("first\n"))
+		Expect(result.Steps[1].Index).To(Equal(3))
+		Expect(result.Steps[1].Passed()).To(BeFalse())
+		Expect(result.Steps[1].CanFail).To(BeTrue())
+	})
+
+	It("should report failed and skipped steps", func() {
+		// Given
+		sut := demo.NewRun("Verified")
+		sut.Step(nil, demo.S("echo broken; exit 2"))
+		sut.Step(nil, demo.S("echo never"))
+
+		// When
+		result := sut.Verify(&demo.Options{})
+
+		// Then
+		Expect(result.Passed()).To(BeFalse())
+		Expect(result.Steps).To(HaveLen(2))
+		Expect(result.Steps[0].Err).To(HaveOccurred())
+		Expect(result.Steps[0].Output).To(Equal("broken\n"))
+		Expect(result.Steps[1].Skipped).To(BeTrue())
+	})
+
+	It("should not write to the run output", func() {
+		// Given
+		sut := demo.NewRun("Verified")
+		sut.Step(nil, demo.S("echo hidden"))
+
+		out := &errorWriter{}
+		Expect(sut.SetOutput(out)).To(Succeed())
+
+		// When
+		result := sut.Verify(&demo.Options{ContinueOnError: true, DryRun: true})
+
+		// Then
+		Expect(result.Passed()).To(BeTrue())
+		Expect(result.Steps[0].Output).To(Equal("hidden\n"))
+	})
+})
+
+var _ = Describe("Demo verification", func() {
+	var junitPath, jsonPath string
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		junitPath = filepath.Join(dir, "junit.xml")
+		jsonPath = filepath.Join(dir, "report.json")
+	})
+
+	It("should verify all runs and write the reports", func() {
+		withArgs([]string{
+			appName, "--verify", "--junit-report", junitPath, "--json-report", jsonPath,
+		}, func() {
+			run1 := demo.NewRun("Run 1")
+			run1.Step(demo.S("Hello"), demo.S("echo hello"))
+
+			run2 := demo.NewRun("Run 2")
+			run2.StepCanFail(nil, demo.S("exit 1"))
+
+			sut := demo.New()
+			sut.Add(run1, "run1", "first run")
+			sut.Add(run2, "run2", "second run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		junit, err := os.ReadFile(junitPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(junit)).To(ContainSubstring(`<testsuite name="Run 1" tests="1" failures="0"`))
+		Expect(string(junit)).To(ContainSubstring(`<testcase name="[1] Hello" classname="Run 1"`))
+
+		data, err := os.ReadFile(jsonPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		report := map[string]any{}
+		Expect(json.Unmarshal(data, &report)).To(Succeed())
+		Expect(report).To(HaveKeyWithValue("passed", true))
+		Expect(report["runs"]).To(HaveLen(2))
+	})
+
+	It("should expand the titles of the runs", func() {
+		out := &strings.Builder{}
+
+		withArgs([]string{
+			appName, "--verify", "--json-report", jsonPath, "--set", "v=2.0",
+		}, func() {
+			r := demo.NewRun("T ${{ .Data.v }}")
+			r.Step(nil, demo.S("echo hello"))
+
+			sut := demo.New()
+			sut.Writer = out
+			sut.Add(r, "run1", "templated run")
+
+			Expect(sut.RunE()).To(Succeed())
+		})
+
+		Expect(out.String()).To(HavePrefix("PASS T 2.0 "))
+
+		data, err := os.ReadFile(jsonPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"title": "T 2.0"`))
+	})
+
+	It("should fail if a step fails", func() {
+		withArgs([]string{
+			appName, "--verify", "--junit-report", junitPath, "--json-report", jsonPath,
+		}, func() {
+			r := demo.NewRun("Failing")
+			r.Step(nil, demo.S("exit 1"))
+			r.Step(nil, demo.S("echo skipped"))
+
+			sut := demo.New()
+			sut.Add(r, "run1", "failing run")
+
+			Expect(sut.RunE()).To(MatchError(ContainSubstring("verification failed: 1 of 1 runs failed")))
+		})
+
+		junit, err := os.ReadFile(junitPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(junit)).To(ContainSubstring(`failures="1" skipped="1"`))
+		Expect(string(junit)).To(ContainSubstring("<failure"))
+		Expect(string(junit)).To(ContainSubstring("<skipped"))
+
+		data, err := os.ReadFile(jsonPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"status": "skipped"`))
+	})
+})